@@ -87,24 +87,9 @@ func (command *BuildsCommand) Execute([]string) error {
 			buildCell.Contents = b.Name
 		}
 
-		var statusCell ui.TableCell
-		statusCell.Contents = b.Status
-
-		switch b.Status {
-		case "pending":
-			statusCell.Color = ui.PendingColor
-		case "started":
-			statusCell.Color = ui.StartedColor
-		case "succeeded":
-			statusCell.Color = ui.SucceededColor
-		case "failed":
-			statusCell.Color = ui.FailedColor
-		case "errored":
-			statusCell.Color = ui.ErroredColor
-		case "aborted":
-			statusCell.Color = ui.AbortedColor
-		case "paused":
-			statusCell.Color = ui.PausedColor
+		statusCell := ui.TableCell{
+			Contents: b.Status,
+			Color:    ui.StatusColor(b.Status),
 		}
 
 		table.Data = append(table.Data, []ui.TableCell{
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/concourse/fly/commands/internal/executehelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type DownloadOutputsCommand struct {
+	Build   string                       `short:"b" long:"build"  required:"true"  description:"The one-off build, executed with --detach, to download the outputs of"`
+	Outputs []flaghelpers.OutputPairFlag `short:"o" long:"output" value-name:"NAME=PATH" description:"Download an output to a different path than was given to execute (can be specified multiple times)"`
+}
+
+func (command *DownloadOutputsCommand) Execute(args []string) error {
+	buildID, err := strconv.Atoi(command.Build)
+	if err != nil {
+		return fmt.Errorf("invalid build ID '%s'", command.Build)
+	}
+
+	client, err := rc.TargetClient(Fly.Target)
+	if err != nil {
+		return err
+	}
+	err = rc.ValidateClient(client, Fly.Target)
+	if err != nil {
+		return err
+	}
+
+	detachedOutputs, err := rc.LoadDetachedOutputs(Fly.Target, buildID)
+	if err != nil {
+		return err
+	}
+
+	for _, override := range command.Outputs {
+		found := false
+		for i, output := range detachedOutputs {
+			if output.Name == override.Name {
+				detachedOutputs[i].Path = override.Path
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("unknown output '%s'", override.Name)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "waiting for build %d's outputs...\n", buildID)
+
+	downloaded := make(chan string, len(detachedOutputs))
+	for _, output := range detachedOutputs {
+		go func(output rc.DetachedOutput) {
+			downloadDetachedOutput(client, output)
+			downloaded <- output.Name
+		}(output)
+	}
+
+	for range detachedOutputs {
+		fmt.Fprintf(os.Stderr, "downloaded %s\n", <-downloaded)
+	}
+
+	// a failed download panics, so the outputs are only forgotten once they
+	// have all been downloaded
+	return rc.RemoveDetachedOutputs(Fly.Target, buildID)
+}
+
+func downloadDetachedOutput(client concourse.Client, detachedOutput rc.DetachedOutput) {
+	var compression flaghelpers.CompressionFlag
+	err := compression.UnmarshalFlag(detachedOutput.Compression)
+	if err != nil {
+		panic(err)
+	}
+
+	path := detachedOutput.Path
+	if path != executehelpers.StdoutPath {
+		path, err = filepath.Abs(path)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	output := executehelpers.Output{
+		Name: detachedOutput.Name,
+		Path: path,
+	}
+	output.Pipe.ReadURL = detachedOutput.ReadURL
+
+	executehelpers.Download(client, output, compression)
+}
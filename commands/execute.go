@@ -1,11 +1,17 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/commands/internal/executehelpers"
@@ -13,20 +19,80 @@ import (
 	"github.com/concourse/fly/config"
 	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/template"
+	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
+	"gopkg.in/yaml.v2"
 )
 
+const watchDebounce = 500 * time.Millisecond
+
+// builds that time out exit like timeout(1), distinctly from the exit codes
+// of failed, errored and aborted builds
+const timeoutExitCode = 124
+
+// how long to wait for a build that has timed out to finish aborting
+const abortGracePeriod = 10 * time.Second
+
 type ExecuteCommand struct {
-	TaskConfig     flaghelpers.PathFlag         `short:"c" long:"config" required:"true"                description:"The task config to execute"`
-	Privileged     bool                         `short:"p" long:"privileged"                            description:"Run the task with full privileges"`
-	ExcludeIgnored bool                         `short:"x" long:"exclude-ignored"                       description:"Skip uploading .gitignored paths. This uses the file paths that are in your Git index. Make sure it's up to date!"`
-	Inputs         []flaghelpers.InputPairFlag  `short:"i" long:"input"       value-name:"NAME=PATH"    description:"An input to provide to the task (can be specified multiple times)"`
-	InputsFrom     flaghelpers.JobFlag          `short:"j" long:"inputs-from" value-name:"PIPELINE/JOB" description:"A job to base the inputs on"`
-	Outputs        []flaghelpers.OutputPairFlag `short:"o" long:"output"      value-name:"NAME=PATH"    description:"An output to fetch from the task (can be specified multiple times)"`
-	Tags           []string                     `          long:"tag"         value-name:"TAG"          description:"A tag for a specific environment (can be specified multiple times)"`
+	TaskConfig     flaghelpers.PathFlag           `short:"c" long:"config"                                description:"The task config to execute"`
+	Privileged     bool                           `short:"p" long:"privileged"                            description:"Run the task with full privileges"`
+	ExcludeIgnored bool                           `short:"x" long:"exclude-ignored"                       description:"Skip uploading .gitignored paths. This uses the file paths that are in your Git index. Make sure it's up to date!"`
+	Inputs         []flaghelpers.InputPairFlag    `short:"i" long:"input"       value-name:"NAME=PATH"    description:"An input to provide to the task (can be specified multiple times)"`
+	InputsFrom     flaghelpers.JobFlag            `short:"j" long:"inputs-from" value-name:"PIPELINE/JOB" description:"A job to base the inputs on"`
+	InputMappings  []flaghelpers.InputMappingFlag `short:"m" long:"input-mapping" value-name:"TASK-INPUT=JOB-INPUT" description:"Take a task input from a differently named input of the --inputs-from job (can be specified multiple times)"`
+	Task           string                         `          long:"task"        value-name:"NAME"         description:"Run the named task step of the --inputs-from job, with its config, params and input mappings"`
+	Outputs        []flaghelpers.OutputFlag       `short:"o" long:"output"      value-name:"NAME=PATH|FORMAT" description:"An output to fetch from the task (can be specified multiple times), or how to print the build's events: text, or json for one JSON object per event. A PATH of - writes a tarball to stdout, and one ending in .tar, .tgz or .tar.gz saves an archive instead of extracting"`
+	Tags           []string                       `          long:"tag"         value-name:"TAG"          description:"A tag for a specific environment (can be specified multiple times)"`
+	Image          string                         `          long:"image"       value-name:"URI"          description:"An image to run the task with, instead of the one in the task config"`
+	ImageFrom      flaghelpers.JobResourceFlag    `          long:"image-from"  value-name:"PIPELINE/JOB:RESOURCE" description:"Run the task with the image resource from a job's inputs, at the version it would use"`
+	Var            []flaghelpers.VariablePairFlag `short:"v" long:"var"            value-name:"[SECRET=KEY]" description:"Variable flag that can be used for filling in template values in the task config"`
+	VarsFrom       []flaghelpers.PathFlag         `short:"l" long:"load-vars-from"                           description:"Variable flag that can be used for filling in template values in the task config from a YAML file"`
+	ParamsFiles    []flaghelpers.PathFlag         `          long:"params-file"    value-name:"PATH"         description:"A YAML file of params to set on the task, taking precedence over the environment (can be specified multiple times)"`
+	Compression    flaghelpers.CompressionFlag    `          long:"compression" value-name:"ENCODING[:LEVEL]" default:"gzip" description:"How to compress inputs and outputs in transit: none, or gzip with an optional level"`
+	Watch          bool                           `          long:"watch"                                 description:"Keep running, and re-run the task whenever the inputs or task config change"`
+	DryRun         bool                           `          long:"dry-run"                               description:"Resolve inputs and outputs and print the build plan, without running it"`
+	PrintPlan      string                         `          long:"print-plan" value-name:"FORMAT" optional:"true" optional-value:"yaml" choice:"yaml" choice:"json" description:"Like --dry-run, printing the build plan as yaml or json"`
+	Local          bool                           `          long:"local"                                 description:"Run the task on this machine, without a target, in place of a container"`
+	Timeout        time.Duration                  `          long:"timeout"     value-name:"DURATION"     description:"Abort the build if it has not finished after this long, exiting 124"`
+	Matrix         flaghelpers.PathFlag           `          long:"matrix"      value-name:"PATH"         description:"Run a build for each combination of the tag sets and param sets listed under 'tags' and 'params' in a YAML file"`
+	Detach         bool                           `          long:"detach"                                description:"Exit once the inputs are uploaded, leaving the build running. Watch it with 'watch -b' and fetch its outputs with 'download-outputs'"`
+	Timestamps     string                         `          long:"timestamps"  value-name:"FORMAT" optional:"true" optional-value:"absolute" choice:"absolute" choice:"relative" description:"Prefix each line of the build's log with the time it was printed, or with the time since the build started"`
+	Summary        bool                           `          long:"summary"                               description:"Once the build finishes, print a table of its steps with how long each took"`
+	LogFile        string                         `          long:"log-file"    value-name:"PATH"         description:"Also write the build's output to a file, without colors"`
+	LogFileANSI    bool                           `          long:"log-file-keep-ansi"                    description:"Keep colors and other escape sequences in the --log-file copy"`
+
+	logFile io.WriteCloser
+
+	// --output is both the outputs to fetch and the format of the build's
+	// events, which are split out of it before anything else
+	outputs      []flaghelpers.OutputPairFlag
+	outputFormat string
 }
 
 func (command *ExecuteCommand) Execute(args []string) error {
+	command.splitOutputs()
+
+	if command.LogFile != "" {
+		var err error
+		command.logFile, err = openLogFile(command.LogFile, command.LogFileANSI)
+		if err != nil {
+			return err
+		}
+
+		defer closeLogFile(command.logFile)
+	}
+
+	// the matrix is checked for first, as --local, --dry-run and
+	// --print-plan would otherwise each take over from it
+	if command.Matrix != "" && (command.Local || command.DryRun || command.PrintPlan != "") {
+		return errors.New("--matrix cannot be used with --local, --dry-run or --print-plan")
+	}
+
+	if command.Local {
+		return command.runLocal(args)
+	}
+
 	client, err := rc.TargetClient(Fly.Target)
 	if err != nil {
 		return err
@@ -36,59 +102,451 @@ func (command *ExecuteCommand) Execute(args []string) error {
 		return err
 	}
 
-	taskConfigFile := command.TaskConfig
-	excludeIgnored := command.ExcludeIgnored
+	if command.DryRun || command.PrintPlan != "" {
+		return command.printPlan(client, args)
+	}
+
+	if command.Matrix != "" {
+		return command.runMatrix(client, args)
+	}
+
+	if command.Detach {
+		return command.detach(client, args)
+	}
+
+	if command.Watch {
+		return command.watch(client, args)
+	}
+
+	build, inputs, outputs, err := command.createBuild(client, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(command.messageWriter(), "executing build", build.ID)
+
+	terminate := make(chan os.Signal, 1)
+
+	go abortOnSignal(client, terminate, command.closeLogFile, build)
+
+	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+
+	timedOut := make(chan struct{})
+	if command.Timeout > 0 {
+		go abortOnTimeout(command.Timeout, timedOut, func() error {
+			return client.AbortBuild(strconv.Itoa(build.ID))
+		}, command.closeLogFile)
+	}
+
+	exitCode, err := command.streamBuild(client, build, inputs, outputs)
+	if err != nil {
+		return err
+	}
+
+	command.exit(timeoutExitCodeFor(exitCode, timedOut))
+
+	return nil
+}
+
+func (command *ExecuteCommand) runLocal(args []string) error {
+	switch {
+	case command.InputsFrom.PipelineName != "" || command.InputsFrom.JobName != "" || command.Task != "":
+		return errors.New("--local cannot be used with --inputs-from or --task")
+	case command.ImageFrom.ResourceName != "":
+		return errors.New("--local cannot be used with --image-from")
+	case command.Watch, command.DryRun, command.PrintPlan != "", command.Detach, command.Matrix != "":
+		return errors.New("--local cannot be used with --watch, --dry-run, --print-plan, --detach or --matrix")
+	}
+
+	taskConfig, err := command.loadTaskConfig(atc.PlanConfig{}, args)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := executehelpers.DetermineLocalInputs(taskConfig.Inputs, command.Inputs)
+	if err != nil {
+		return err
+	}
+
+	outputs, err := executehelpers.DetermineLocalOutputs(taskConfig.Outputs, command.outputs)
+	if err != nil {
+		return err
+	}
+
+	run := executehelpers.RunLocal(taskConfig, inputs, outputs, command.ExcludeIgnored)
+
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-terminate
+		fmt.Fprintf(os.Stderr, "\naborting...\n")
+		run.Abort()
+
+		<-terminate
+		fmt.Fprintln(os.Stderr, "exiting immediately")
+		command.exit(2)
+	}()
+
+	timedOut := make(chan struct{})
+	if command.Timeout > 0 {
+		go abortOnTimeout(command.Timeout, timedOut, func() error {
+			run.Abort()
+			return nil
+		}, command.closeLogFile)
+	}
+
+	exitCode := eventstream.RenderWith(command.renderer(), run)
+	run.Close()
+
+	command.exit(timeoutExitCodeFor(exitCode, timedOut))
+
+	return nil
+}
+
+func (command *ExecuteCommand) detach(client concourse.Client, args []string) error {
+	if command.Watch || command.Timeout > 0 {
+		return errors.New("--detach cannot be used with --watch or --timeout")
+	}
 
-	taskConfig, err := config.LoadTaskConfig(string(taskConfigFile), args)
+	build, inputs, outputs, err := command.createBuild(client, args)
 	if err != nil {
 		return err
 	}
 
+	fmt.Println("executing build", build.ID)
+
+	if len(outputs) > 0 {
+		detachedOutputs := []rc.DetachedOutput{}
+		for _, output := range outputs {
+			detachedOutputs = append(detachedOutputs, rc.DetachedOutput{
+				Name:        output.Name,
+				Path:        output.Path,
+				ReadURL:     output.Pipe.ReadURL,
+				Compression: command.Compression.String(),
+			})
+		}
+
+		err := rc.SaveDetachedOutputs(Fly.Target, build.ID, detachedOutputs)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the build can't run until its inputs are uploaded, so it is aborted if
+	// fly is interrupted before then, as it would be if attached
+	terminate := make(chan os.Signal, 1)
+
+	go abortOnSignal(client, terminate, command.closeLogFile, build)
+
+	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+
+	for _, input := range inputs {
+		if input.Path == "" {
+			continue
+		}
+
+		err := executehelpers.Upload(client, input, command.ExcludeIgnored, command.Compression)
+		if err != nil {
+			abortErr := client.AbortBuild(strconv.Itoa(build.ID))
+			if abortErr != nil {
+				fmt.Fprintln(os.Stderr, "failed to abort:", abortErr)
+			}
+
+			return fmt.Errorf("failed to upload input '%s', aborted build %d: %s", input.Name, build.ID, err)
+		}
+	}
+
+	signal.Stop(terminate)
+
+	fmt.Println("")
+	fmt.Println("detached. to re-attach, run:")
+	fmt.Println("")
+	fmt.Println("    " + ui.Embolden("fly -t %s watch -b %d", Fly.Target, build.ID))
+
+	if len(outputs) > 0 {
+		fmt.Println("")
+		fmt.Println("and to download its outputs once it finishes, run:")
+		fmt.Println("")
+		fmt.Println("    " + ui.Embolden("fly -t %s download-outputs -b %d", Fly.Target, build.ID))
+	}
+
+	return nil
+}
+
+func (command *ExecuteCommand) createBuild(client concourse.Client, args []string) (atc.Build, []executehelpers.Input, []executehelpers.Output, error) {
+	plan, inputs, outputs, err := command.preparePlan(client, args)
+	if err != nil {
+		return atc.Build{}, nil, nil, err
+	}
+
+	build, err := client.CreateBuild(plan)
+	if err != nil {
+		return atc.Build{}, nil, nil, err
+	}
+
+	saveBuildPlan(build, plan)
+
+	return build, inputs, outputs, nil
+}
+
+func (command *ExecuteCommand) preparePlan(client concourse.Client, args []string) (atc.Plan, []executehelpers.Input, []executehelpers.Output, error) {
+	return command.prepareCombinationPlan(client, args, executehelpers.MatrixCombination{})
+}
+
+// prepareCombinationPlan prepares the plan with the combination's tags and
+// params taking precedence over any others
+func (command *ExecuteCommand) prepareCombinationPlan(client concourse.Client, args []string, combination executehelpers.MatrixCombination) (atc.Plan, []executehelpers.Input, []executehelpers.Output, error) {
+	var jobTask atc.PlanConfig
+	if command.Task != "" {
+		if command.InputsFrom.PipelineName == "" && command.InputsFrom.JobName == "" {
+			return atc.Plan{}, nil, nil, errors.New("--task requires --inputs-from")
+		}
+
+		var err error
+		jobTask, err = executehelpers.FindJobTask(client, command.InputsFrom, command.Task)
+		if err != nil {
+			return atc.Plan{}, nil, nil, err
+		}
+	}
+
+	taskConfig, err := command.loadTaskConfig(jobTask, args)
+	if err != nil {
+		return atc.Plan{}, nil, nil, err
+	}
+
+	if len(combination.Params) > 0 && taskConfig.Params == nil {
+		taskConfig.Params = map[string]string{}
+	}
+
+	for name, value := range combination.Params {
+		taskConfig.Params[name] = value
+	}
+
+	if command.Image != "" && command.ImageFrom.ResourceName != "" {
+		return atc.Plan{}, nil, nil, errors.New("only one of --image and --image-from may be specified")
+	}
+
+	imageFrom := command.ImageFrom
+	if jobTask.ImageArtifactName != "" && command.Image == "" && imageFrom.ResourceName == "" {
+		imageFrom = flaghelpers.JobResourceFlag{
+			PipelineName: command.InputsFrom.PipelineName,
+			JobName:      command.InputsFrom.JobName,
+			ResourceName: jobTask.ImageArtifactName,
+		}
+	}
+
+	if command.Image != "" {
+		taskConfig.Image = command.Image
+		taskConfig.ImageResource = nil
+	}
+
+	image, err := executehelpers.FetchImageFromJob(client, imageFrom)
+	if err != nil {
+		return atc.Plan{}, nil, nil, err
+	}
+
+	if image != nil {
+		taskConfig.Image = ""
+		taskConfig.ImageResource = nil
+	}
+
+	jobInputMappings := map[string]string{}
+	for name, mappedName := range jobTask.InputMapping {
+		jobInputMappings[name] = mappedName
+	}
+
+	for _, mapping := range command.InputMappings {
+		jobInputMappings[mapping.Name] = mapping.MappedName
+	}
+
 	inputs, err := executehelpers.DetermineInputs(
 		client,
 		taskConfig.Inputs,
-		command.Inputs,
+		command.taskInputs(jobTask, taskConfig),
+		jobInputMappings,
 		command.InputsFrom,
 	)
 	if err != nil {
-		return err
+		return atc.Plan{}, nil, nil, err
 	}
 
 	outputs, err := executehelpers.DetermineOutputs(
 		client,
 		taskConfig.Outputs,
-		command.Outputs,
+		command.outputs,
 	)
 	if err != nil {
-		return err
+		return atc.Plan{}, nil, nil, err
 	}
 
-	build, err := executehelpers.CreateBuild(
-		client,
-		command.Privileged,
+	tags := command.Tags
+	if len(tags) == 0 {
+		tags = jobTask.Tags
+	}
+
+	if len(combination.Tags) > 0 {
+		tags = combination.Tags
+	}
+
+	plan, err := executehelpers.BuildPlan(
+		command.Privileged || jobTask.Privileged,
 		inputs,
+		image,
 		outputs,
 		taskConfig,
-		command.Tags,
+		tags,
+		command.Compression,
 		Fly.Target,
 	)
 	if err != nil {
-		return err
+		return atc.Plan{}, nil, nil, err
 	}
 
-	fmt.Println("executing build", build.ID)
+	return plan, inputs, outputs, nil
+}
 
-	terminate := make(chan os.Signal, 1)
+func (command *ExecuteCommand) loadTaskConfig(jobTask atc.PlanConfig, args []string) (atc.TaskConfig, error) {
+	templateVariables, err := command.templateVariables()
+	if err != nil {
+		return atc.TaskConfig{}, err
+	}
 
-	go abortOnSignal(client, terminate, build)
+	var taskConfig atc.TaskConfig
 
-	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+	switch {
+	case command.TaskConfig != "":
+		taskConfig, err = config.ReadTaskConfig(string(command.TaskConfig), templateVariables)
+
+	case jobTask.TaskConfig != nil:
+		taskConfig = *jobTask.TaskConfig
+
+	case jobTask.TaskConfigPath != "":
+		var configPath string
+		configPath, err = command.localTaskConfigPath(jobTask)
+		if err == nil {
+			taskConfig, err = config.ReadTaskConfig(configPath, templateVariables)
+		}
+
+	default:
+		err = errors.New("either --config or --task must be specified")
+	}
+	if err != nil {
+		return atc.TaskConfig{}, err
+	}
 
+	var paramsFiles []string
+	for _, path := range command.ParamsFiles {
+		paramsFiles = append(paramsFiles, string(path))
+	}
+
+	return config.OverrideTaskConfig(taskConfig, args, jobTask.Params, paramsFiles)
+}
+
+// the job's task config lives in one of its artifacts, so it can only be
+// read when that artifact is provided locally
+func (command *ExecuteCommand) localTaskConfigPath(jobTask atc.PlanConfig) (string, error) {
+	segments := strings.SplitN(jobTask.TaskConfigPath, "/", 2)
+	if len(segments) != 2 {
+		return "", fmt.Errorf("invalid task config path `%s`", jobTask.TaskConfigPath)
+	}
+
+	artifactName := segments[0]
+
+	for _, input := range command.Inputs {
+		if input.Name == artifactName || jobTask.InputMapping[input.Name] == artifactName {
+			return filepath.Join(input.Path, filepath.FromSlash(segments[1])), nil
+		}
+	}
+
+	return "", fmt.Errorf("task config `%s` comes from input `%s`; provide it with -i %s=PATH, or specify -c", jobTask.TaskConfigPath, artifactName, artifactName)
+}
+
+// taskInputs leaves out the -i that only holds the job task's config, which
+// is read locally rather than uploaded, e.g. from a separate ci repo
+func (command *ExecuteCommand) taskInputs(jobTask atc.PlanConfig, taskConfig atc.TaskConfig) []flaghelpers.InputPairFlag {
+	if command.TaskConfig != "" || jobTask.TaskConfig != nil || jobTask.TaskConfigPath == "" {
+		return command.Inputs
+	}
+
+	artifactName := strings.SplitN(jobTask.TaskConfigPath, "/", 2)[0]
+
+	inputs := []flaghelpers.InputPairFlag{}
+	for _, input := range command.Inputs {
+		if input.Name == artifactName && !executehelpers.TaskInputsContainsName(taskConfig.Inputs, input.Name) {
+			continue
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	return inputs
+}
+
+func (command *ExecuteCommand) templateVariables() (template.Variables, error) {
+	if len(command.Var) == 0 && len(command.VarsFrom) == 0 {
+		return nil, nil
+	}
+
+	var variables template.Variables
+
+	for _, path := range command.VarsFrom {
+		fileVars, err := template.LoadVariablesFromFile(string(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variables from file (%s): %s", string(path), err)
+		}
+
+		variables = variables.Merge(fileVars)
+	}
+
+	flagVars := template.Variables{}
+	for _, v := range command.Var {
+		flagVars[v.Name] = v.Value
+	}
+
+	return variables.Merge(flagVars), nil
+}
+
+func (command *ExecuteCommand) printPlan(client concourse.Client, args []string) error {
+	plan, _, _, err := command.preparePlan(executehelpers.DryRunClient(client), args)
+	if err != nil {
+		return err
+	}
+
+	redacted, err := executehelpers.RedactPlan(plan)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	if command.PrintPlan == "json" {
+		payload, err = json.MarshalIndent(redacted, "", "  ")
+		payload = append(payload, '\n')
+	} else {
+		payload, err = yaml.Marshal(redacted)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Printf("%s", payload)
+	return err
+}
+
+func (command *ExecuteCommand) streamBuild(
+	client concourse.Client,
+	build atc.Build,
+	inputs []executehelpers.Input,
+	outputs []executehelpers.Output,
+) (int, error) {
 	inputChan := make(chan interface{})
 	go func() {
 		for _, i := range inputs {
 			if i.Path != "" {
-				executehelpers.Upload(client, i, excludeIgnored)
+				err := executehelpers.Upload(client, i, command.ExcludeIgnored, command.Compression)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to upload input '%s': %s\n", i.Name, err)
+				}
 			}
 		}
 		close(inputChan)
@@ -100,7 +558,7 @@ func (command *ExecuteCommand) Execute(args []string) error {
 			outputChans = append(outputChans, make(chan interface{}, 1))
 			go func(o executehelpers.Output, outputChan chan<- interface{}) {
 				if o.Path != "" {
-					executehelpers.Download(client, o)
+					executehelpers.Download(client, o, command.Compression)
 				}
 
 				close(outputChan)
@@ -110,10 +568,10 @@ func (command *ExecuteCommand) Execute(args []string) error {
 
 	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", build.ID))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	exitCode := eventstream.RenderWith(command.renderer(), eventSource)
 	eventSource.Close()
 
 	<-inputChan
@@ -124,21 +582,258 @@ func (command *ExecuteCommand) Execute(args []string) error {
 		}
 	}
 
+	return exitCode, nil
+}
+
+func (command *ExecuteCommand) watch(client concourse.Client, args []string) error {
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+
+	changes := executehelpers.WatchForChanges(command.watchedPaths(), command.outputPaths(), command.ExcludeIgnored, watchDebounce)
+
+	for run := 1; ; run++ {
+		if run > 1 {
+			fmt.Fprintln(command.messageWriter(), "")
+			fmt.Fprintln(command.messageWriter(), ui.Embolden("=== %s: change detected, running again (#%d) ===", time.Now().Format("15:04:05"), run))
+			fmt.Fprintln(command.messageWriter(), "")
+		}
+
+		started := time.Now()
+		timeout := timeoutAfter(command.Timeout)
+
+		build, inputs, outputs, err := command.createBuild(client, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to execute:", err)
+		} else {
+			fmt.Fprintln(command.messageWriter(), "executing build", build.ID)
+
+			finished := make(chan int, 1)
+			go func() {
+				exitCode, err := command.streamBuild(client, build, inputs, outputs)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "failed to stream build:", err)
+					exitCode = 255
+				}
+
+				finished <- exitCode
+			}()
+
+			select {
+			case exitCode := <-finished:
+				command.printWatchSummary(build, exitCode, time.Since(started))
+
+			case <-changes:
+				fmt.Fprintf(os.Stderr, "\nchange detected, aborting build %d...\n", build.ID)
+
+				err := client.AbortBuild(strconv.Itoa(build.ID))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "failed to abort:", err)
+				}
+
+				command.printWatchSummary(build, <-finished, time.Since(started))
+
+				continue
+
+			case <-timeout:
+				fmt.Fprintf(os.Stderr, "\ntimed out after %s, aborting build %d...\n", command.Timeout, build.ID)
+
+				err := client.AbortBuild(strconv.Itoa(build.ID))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "failed to abort:", err)
+				}
+
+				select {
+				case <-finished:
+				case <-time.After(abortGracePeriod):
+					fmt.Fprintln(os.Stderr, "build did not finish aborting")
+				}
+
+				command.printWatchSummary(build, timeoutExitCode, time.Since(started))
+
+			case <-terminate:
+				fmt.Fprintf(os.Stderr, "\naborting...\n")
+
+				err := client.AbortBuild(strconv.Itoa(build.ID))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "failed to abort:", err)
+					command.exit(2)
+				}
+
+				select {
+				case exitCode := <-finished:
+					command.printWatchSummary(build, exitCode, time.Since(started))
+					command.exit(exitCode)
+				case <-terminate:
+					fmt.Fprintln(os.Stderr, "exiting immediately")
+					command.exit(2)
+				}
+			}
+		}
+
+		fmt.Fprintln(command.messageWriter(), "waiting for changes...")
+
+		select {
+		case <-changes:
+		case <-terminate:
+			return nil
+		}
+	}
+}
+
+func (command *ExecuteCommand) splitOutputs() {
+	command.outputFormat = eventstream.TextFormat
+
+	for _, output := range command.Outputs {
+		if output.Format != "" {
+			command.outputFormat = output.Format
+		} else {
+			command.outputs = append(command.outputs, output.Pair)
+		}
+	}
+}
+
+// the build's log goes to stderr when an output is written to stdout, so
+// that the two don't interleave, and to the log file too if there is one
+func (command *ExecuteCommand) logWriter() io.Writer {
+	for _, output := range command.outputs {
+		if output.Path == executehelpers.StdoutPath {
+			return teeLog(os.Stderr, command.logFile)
+		}
+	}
+
+	return teeLog(os.Stdout, command.logFile)
+}
+
+func (command *ExecuteCommand) renderer() eventstream.Renderer {
+	return eventstream.NewRenderer(command.outputFormat, command.logWriter(), command.renderOptions())
+}
+
+func (command *ExecuteCommand) renderOptions() eventstream.RenderOptions {
+	return eventstream.RenderOptions{
+		Timestamps: command.Timestamps,
+		Summary:    command.Summary,
+	}
+}
+
+// messages about the build go alongside its log, unless the log is JSON
+func (command *ExecuteCommand) messageWriter() io.Writer {
+	if command.outputFormat == eventstream.JSONFormat {
+		return os.Stderr
+	}
+
+	return command.logWriter()
+}
+
+func (command *ExecuteCommand) watchedPaths() []string {
+	paths := []string{string(command.TaskConfig)}
+
+	for _, path := range command.VarsFrom {
+		paths = append(paths, string(path))
+	}
+
+	for _, path := range command.ParamsFiles {
+		paths = append(paths, string(path))
+	}
+
+	if len(command.Inputs) == 0 && command.InputsFrom.PipelineName == "" && command.InputsFrom.JobName == "" {
+		wd, err := os.Getwd()
+		if err == nil {
+			paths = append(paths, wd)
+		}
+	}
+
+	for _, input := range command.Inputs {
+		paths = append(paths, input.Path)
+	}
+
+	return paths
+}
+
+// outputPaths are where the task's outputs are downloaded to, which must not
+// count as changes, or every run would trigger the next
+func (command *ExecuteCommand) outputPaths() []string {
+	paths := []string{}
+	for _, output := range command.outputs {
+		if output.Path != executehelpers.StdoutPath {
+			paths = append(paths, output.Path)
+		}
+	}
+
+	return paths
+}
+
+func (command *ExecuteCommand) printWatchSummary(build atc.Build, exitCode int, elapsed time.Duration) {
+	fmt.Fprintln(command.messageWriter(), "")
+	fmt.Fprintln(command.messageWriter(), ui.Embolden("build %d exited %d after %s", build.ID, exitCode, elapsed.Truncate(100*time.Millisecond)))
+}
+
+func (command *ExecuteCommand) closeLogFile() {
+	closeLogFile(command.logFile)
+}
+
+// exit closes the log file first, as os.Exit skips deferred calls
+func (command *ExecuteCommand) exit(exitCode int) {
+	command.closeLogFile()
 	os.Exit(exitCode)
+}
 
-	return nil
+// abortOnTimeout aborts the build once timeout has passed, closing timedOut
+// first. If the build has not finished aborting within the grace period,
+// fly exits anyway, running cleanup first if it is given, as os.Exit skips
+// deferred calls.
+func abortOnTimeout(timeout time.Duration, timedOut chan<- struct{}, abort func() error, cleanup func()) {
+	<-time.After(timeout)
+
+	close(timedOut)
+
+	fmt.Fprintf(os.Stderr, "\ntimed out after %s, aborting...\n", timeout)
+
+	err := abort()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to abort:", err)
+		exitAfter(cleanup, timeoutExitCode)
+	}
+
+	<-time.After(abortGracePeriod)
+
+	fmt.Fprintln(os.Stderr, "build did not finish aborting, exiting")
+	exitAfter(cleanup, timeoutExitCode)
+}
+
+// a build that didn't succeed after timing out exits with timeoutExitCode,
+// rather than as aborted
+func timeoutExitCodeFor(exitCode int, timedOut <-chan struct{}) int {
+	select {
+	case <-timedOut:
+		if exitCode != 0 {
+			return timeoutExitCode
+		}
+	default:
+	}
+
+	return exitCode
+}
+
+// timeoutAfter is like time.After, but never fires for a zero timeout
+func timeoutAfter(timeout time.Duration) <-chan time.Time {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return time.After(timeout)
 }
 
 func abortOnSignal(
 	client concourse.Client,
 	terminate <-chan os.Signal,
-	build atc.Build,
+	cleanup func(),
+	builds ...atc.Build,
 ) {
 	<-terminate
 
 	fmt.Fprintf(os.Stderr, "\naborting...\n")
 
-	err := client.AbortBuild(strconv.Itoa(build.ID))
+	err := abortBuilds(client, builds)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to abort:", err)
 		return
@@ -147,5 +842,24 @@ func abortOnSignal(
 	// if told to terminate again, exit immediately
 	<-terminate
 	fmt.Fprintln(os.Stderr, "exiting immediately")
-	os.Exit(2)
+	exitAfter(cleanup, 2)
+}
+
+func exitAfter(cleanup func(), exitCode int) {
+	if cleanup != nil {
+		cleanup()
+	}
+
+	os.Exit(exitCode)
+}
+
+func abortBuilds(client concourse.Client, builds []atc.Build) error {
+	for _, build := range builds {
+		err := client.AbortBuild(strconv.Itoa(build.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/executehelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+type matrixBuild struct {
+	combination executehelpers.MatrixCombination
+
+	plan   atc.Plan
+	inputs []executehelpers.Input
+
+	build    atc.Build
+	exitCode int
+}
+
+// runMatrix runs a build for each combination in the matrix, streaming
+// their logs side by side. Pipes can only be read once, so each build gets
+// its own, but each input is archived just once and the same archive is
+// uploaded to every build.
+func (command *ExecuteCommand) runMatrix(client concourse.Client, args []string) error {
+	if command.Watch || command.Detach {
+		return errors.New("--matrix cannot be used with --watch or --detach")
+	}
+
+	if len(command.outputs) > 0 {
+		return errors.New("--matrix cannot be used with --output NAME=PATH, as the builds' outputs would overwrite each other")
+	}
+
+	if command.outputFormat == eventstream.JSONFormat {
+		return errors.New("--matrix cannot be used with --output json, as the builds' logs are interleaved")
+	}
+
+	matrix, err := executehelpers.LoadMatrix(string(command.Matrix))
+	if err != nil {
+		return err
+	}
+
+	builds := []*matrixBuild{}
+	for _, combination := range matrix.Combinations() {
+		plan, inputs, _, err := command.prepareCombinationPlan(client, args, combination)
+		if err != nil {
+			return err
+		}
+
+		builds = append(builds, &matrixBuild{
+			combination: combination,
+			plan:        plan,
+			inputs:      inputs,
+		})
+	}
+
+	archives := map[string]string{}
+	removeArchives := func() {
+		for _, archive := range archives {
+			os.Remove(archive)
+		}
+	}
+
+	defer removeArchives()
+
+	cleanup := func() {
+		removeArchives()
+		command.closeLogFile()
+	}
+
+	for _, input := range builds[0].inputs {
+		if input.Path == "" {
+			continue
+		}
+
+		archive, err := executehelpers.ArchiveInput(input, command.ExcludeIgnored, command.Compression)
+		if err != nil {
+			return err
+		}
+
+		archives[input.Name] = archive
+	}
+
+	createdBuilds := []atc.Build{}
+	for _, b := range builds {
+		b.build, err = client.CreateBuild(b.plan)
+		if err != nil {
+			abortBuilds(client, createdBuilds)
+			return err
+		}
+
+		createdBuilds = append(createdBuilds, b.build)
+
+		saveBuildPlan(b.build, b.plan)
+
+		fmt.Fprintf(command.messageWriter(), "executing build %d: %s\n", b.build.ID, b.combination.Name())
+	}
+
+	terminate := make(chan os.Signal, 1)
+
+	go abortOnSignal(client, terminate, cleanup, createdBuilds...)
+
+	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+
+	timedOut := make(chan struct{})
+	if command.Timeout > 0 {
+		go abortOnTimeout(command.Timeout, timedOut, func() error {
+			return abortBuilds(client, createdBuilds)
+		}, cleanup)
+	}
+
+	wg := new(sync.WaitGroup)
+	for _, b := range builds {
+		wg.Add(1)
+
+		go func(b *matrixBuild) {
+			defer wg.Done()
+
+			b.exitCode = command.streamMatrixBuild(client, b, archives)
+		}(b)
+	}
+
+	wg.Wait()
+
+	exitCode := printMatrixSummary(command.messageWriter(), client, builds)
+
+	removeArchives()
+
+	command.exit(timeoutExitCodeFor(exitCode, timedOut))
+
+	return nil
+}
+
+func (command *ExecuteCommand) streamMatrixBuild(client concourse.Client, b *matrixBuild, archives map[string]string) int {
+	prefix := fmt.Sprintf("[%d] ", b.build.ID)
+	if name := b.combination.Name(); name != "" {
+		prefix = fmt.Sprintf("[%d %s] ", b.build.ID, name)
+	}
+
+	log := ui.NewPrefixWriter(command.logWriter(), prefix)
+	defer log.Flush()
+
+	go func() {
+		for _, input := range b.inputs {
+			if archive, found := archives[input.Name]; found {
+				err := executehelpers.UploadArchive(client, input, archive)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%sfailed to upload input '%s': %s\n", prefix, input.Name, err)
+				}
+			}
+		}
+	}()
+
+	eventSource, err := client.BuildEvents(strconv.Itoa(b.build.ID))
+	if err != nil {
+		fmt.Fprintln(log, "failed to stream build:", err)
+		return 255
+	}
+
+	exitCode := eventstream.RenderWith(eventstream.NewTextRenderer(log, command.renderOptions()), eventSource)
+	eventSource.Close()
+
+	return exitCode
+}
+
+// printMatrixSummary prints a table of the builds' statuses, and returns
+// the highest of their exit codes
+func printMatrixSummary(dst io.Writer, client concourse.Client, builds []*matrixBuild) int {
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "id", Color: color.New(color.Bold)},
+			{Contents: "combination", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+			{Contents: "exit code", Color: color.New(color.Bold)},
+		},
+	}
+
+	exitCode := 0
+	for _, b := range builds {
+		if b.exitCode > exitCode {
+			exitCode = b.exitCode
+		}
+
+		status := "unknown"
+		build, found, err := client.Build(strconv.Itoa(b.build.ID))
+		if err == nil && found {
+			status = build.Status
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: strconv.Itoa(b.build.ID)},
+			{Contents: b.combination.Name()},
+			{Contents: status, Color: ui.StatusColor(status)},
+			{Contents: strconv.Itoa(b.exitCode)},
+		})
+	}
+
+	fmt.Fprintln(dst, "")
+
+	err := table.Render(dst)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to print summary:", err)
+	}
+
+	return exitCode
+}
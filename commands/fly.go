@@ -16,6 +16,11 @@ type FlyCommand struct {
 
 	Execute ExecuteCommand `command:"execute" alias:"e" description:"Execute a one-off build using local bits"`
 	Watch   WatchCommand   `command:"watch"   alias:"w" description:"Stream a build's output"`
+	Replay  ReplayCommand  `command:"replay"           description:"Play back a build's output saved with 'watch --record'"`
+
+	DownloadOutputs DownloadOutputsCommand `command:"download-outputs" alias:"do" description:"Download the outputs of a one-off build executed with --detach"`
+
+	ValidateTask ValidateTaskCommand `command:"validate-task" alias:"vt" description:"Validate a task config without running it"`
 
 	Containers ContainersCommand `command:"containers" alias:"cs" description:"Print the active containers"`
 	Hijack     HijackCommand     `command:"hijack"     alias:"intercept" alias:"i" description:"Execute a command in a container"`
@@ -33,6 +38,7 @@ type FlyCommand struct {
 
 	Builds     BuildsCommand     `command:"builds"      alias:"bs" description:"List builds data"`
 	AbortBuild AbortBuildCommand `command:"abort-build" alias:"ab" description:"Abort a build"`
+	RerunBuild RerunBuildCommand `command:"rerun-build" alias:"rb" description:"Run a build again with the same versions of its inputs. A job's build is rerun with the job's current config, which may have changed since. One-off builds can only be rerun if they were executed from this machine without uploading inputs or downloading outputs"`
 
 	TriggerJob TriggerJobCommand `command:"trigger-job" alias:"tj" description:"Start a job in a pipeline"`
 
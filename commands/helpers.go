@@ -3,9 +3,12 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/executehelpers"
+	"github.com/concourse/fly/rc"
 	"github.com/concourse/go-concourse/concourse"
 )
 
@@ -70,6 +73,42 @@ func GetBuild(client concourse.Client, jobName string, buildNameOrID string, pip
 	}
 }
 
+// GetBuildVersions returns the versions of the resources that the build
+// fetched, by the names of the get steps that fetched them
+func GetBuildVersions(client concourse.Client, build atc.Build) (map[string]atc.Version, error) {
+	resources, found, err := client.BuildResources(build.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build resources %s", err)
+	}
+
+	if !found {
+		return nil, errors.New("build not found")
+	}
+
+	versions := map[string]atc.Version{}
+	for _, input := range resources.Inputs {
+		versions[input.Name] = input.Version
+	}
+
+	return versions, nil
+}
+
+// saveBuildPlan keeps the plan of a one-off build so that rerun-build can
+// submit it again. Plans that upload inputs or download outputs use pipes,
+// which only work once, so those builds cannot be rerun and their plans,
+// which have the target's token in them, are not kept. Not being able to
+// is no reason to fail the build.
+func saveBuildPlan(build atc.Build, plan atc.Plan) {
+	if executehelpers.PlanUsesPipes(plan) {
+		return
+	}
+
+	err := rc.SaveBuildPlan(Fly.Target, build.ID, plan)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save build plan:", err)
+	}
+}
+
 func SliceItoa(slice []int) string {
 	var strSlice string
 	for i, val := range slice {
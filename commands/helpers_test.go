@@ -216,4 +216,48 @@ var _ = Describe("Helper Functions", func() {
 			})
 		})
 	})
+
+	Describe("#GetBuildVersions", func() {
+		var client *fakes.FakeClient
+
+		build := atc.Build{ID: 123, Name: "5", JobName: "myjob"}
+
+		BeforeEach(func() {
+			client = new(fakes.FakeClient)
+		})
+
+		Context("when the build exists", func() {
+			BeforeEach(func() {
+				client.BuildResourcesReturns(atc.BuildInputsOutputs{
+					Inputs: []atc.PublicBuildInput{
+						{Name: "some-input", Resource: "some-resource", Version: atc.Version{"ref": "abc123"}},
+						{Name: "other-input", Resource: "some-resource", Version: atc.Version{"ref": "def456"}},
+					},
+				}, true, nil)
+			})
+
+			It("returns the versions it fetched by the name of each input", func() {
+				versions, err := GetBuildVersions(client, build)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(versions).To(Equal(map[string]atc.Version{
+					"some-input":  {"ref": "abc123"},
+					"other-input": {"ref": "def456"},
+				}))
+
+				Expect(client.BuildResourcesArgsForCall(0)).To(Equal(123))
+			})
+		})
+
+		Context("when the build does not exist", func() {
+			BeforeEach(func() {
+				client.BuildResourcesReturns(atc.BuildInputsOutputs{}, false, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := GetBuildVersions(client, build)
+				Expect(err).To(MatchError("build not found"))
+			})
+		})
+	})
 })
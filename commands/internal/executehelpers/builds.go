@@ -1,31 +1,33 @@
 package executehelpers
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/fly/rc"
-	"github.com/concourse/go-concourse/concourse"
 )
 
-func CreateBuild(
-	client concourse.Client,
+func BuildPlan(
 	privileged bool,
 	inputs []Input,
+	image *Input,
 	outputs []Output,
 	config atc.TaskConfig,
 	tags []string,
+	compression flaghelpers.CompressionFlag,
 	target rc.TargetName,
-) (atc.Build, error) {
+) (atc.Plan, error) {
 	fact := atc.NewPlanFactory(time.Now().Unix())
 
 	if err := config.Validate(); err != nil {
-		return atc.Build{}, err
+		return atc.Plan{}, err
 	}
 
 	targetProps, err := rc.SelectTarget(target)
 	if err != nil {
-		return atc.Build{}, err
+		return atc.Plan{}, err
 	}
 
 	buildInputs := atc.AggregatePlan{}
@@ -44,6 +46,7 @@ func CreateBuild(
 				Name:   input.Name,
 				Type:   "archive",
 				Source: source,
+				Params: compressionParams(compression),
 			}
 		} else {
 			getPlan = atc.GetPlan{
@@ -59,6 +62,21 @@ func CreateBuild(
 		buildInputs = append(buildInputs, fact.NewPlan(getPlan))
 	}
 
+	if image != nil {
+		if TaskInputsContainsName(config.Inputs, image.Name) {
+			return atc.Plan{}, fmt.Errorf("image input `%s` conflicts with an input of the task", image.Name)
+		}
+
+		buildInputs = append(buildInputs, fact.NewPlan(atc.GetPlan{
+			Name:    image.Name,
+			Type:    image.BuildInput.Type,
+			Source:  image.BuildInput.Source,
+			Version: image.BuildInput.Version,
+			Params:  image.BuildInput.Params,
+			Tags:    image.BuildInput.Tags,
+		}))
+	}
+
 	taskPlan := fact.NewPlan(atc.TaskPlan{
 		Name:       "one-off",
 		Privileged: privileged,
@@ -69,15 +87,18 @@ func CreateBuild(
 		taskPlan.Task.Tags = tags
 	}
 
+	if image != nil {
+		taskPlan.Task.ImageArtifactName = image.Name
+	}
+
 	buildOutputs := atc.AggregatePlan{}
 	for _, output := range outputs {
 		source := atc.Source{
 			"uri": output.Pipe.ReadURL,
 		}
 
-		params := atc.Params{
-			"directory": output.Name,
-		}
+		params := compressionParams(compression)
+		params["directory"] = output.Name
 
 		if auth, ok := targetAuthorization(targetProps.Token); ok {
 			source["authorization"] = auth
@@ -107,7 +128,7 @@ func CreateBuild(
 		})
 	}
 
-	return client.CreateBuild(plan)
+	return plan, nil
 }
 
 func targetAuthorization(token *rc.TargetToken) (string, bool) {
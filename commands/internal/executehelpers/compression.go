@@ -0,0 +1,61 @@
+package executehelpers
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+)
+
+func compressionParams(compression flaghelpers.CompressionFlag) atc.Params {
+	params := atc.Params{
+		"compression": encodingOf(compression),
+	}
+
+	if compression.Level != 0 {
+		params["compression_level"] = compression.Level
+	}
+
+	return params
+}
+
+func encodingOf(compression flaghelpers.CompressionFlag) string {
+	if compression.Encoding == "" {
+		return flaghelpers.CompressionGzip
+	}
+
+	return compression.Encoding
+}
+
+func compressedWriter(compression flaghelpers.CompressionFlag, w io.Writer) (io.WriteCloser, error) {
+	switch encodingOf(compression) {
+	case flaghelpers.CompressionNone:
+		return nopWriteCloser{w}, nil
+	default:
+		level := gzip.DefaultCompression
+		if compression.Level != 0 {
+			level = compression.Level
+		}
+
+		return gzip.NewWriterLevel(w, level)
+	}
+}
+
+func compressedReader(compression flaghelpers.CompressionFlag, r io.Reader) (io.ReadCloser, error) {
+	switch encodingOf(compression) {
+	case flaghelpers.CompressionNone:
+		return ioutil.NopCloser(r), nil
+	default:
+		return gzip.NewReader(r)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -2,14 +2,19 @@ package executehelpers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/go-concourse/concourse"
 )
 
-func Download(client concourse.Client, output Output) {
-	path := output.Path
+const StdoutPath = "-"
+
+func Download(client concourse.Client, output Output, compression flaghelpers.CompressionFlag) {
 	pipe := output.Pipe
 
 	response, err := client.HTTPClient().Get(pipe.ReadURL)
@@ -24,13 +29,79 @@ func Download(client concourse.Client, output Output) {
 		panic("unexpected-response-code")
 	}
 
-	err = os.MkdirAll(path, 0755)
+	err = writeOutput(output.Path, response.Body, compression)
 	if err != nil {
 		panic(err)
 	}
+}
+
+// writeOutput writes the tar stream of an output, compressed with
+// compression, to path: as a tarball on stdout, as an archive file, or
+// extracted into a directory.
+func writeOutput(path string, stream io.Reader, compression flaghelpers.CompressionFlag) error {
+	if path == StdoutPath {
+		return writeArchive(os.Stdout, stream, compression, flaghelpers.CompressionFlag{Encoding: flaghelpers.CompressionNone})
+	}
+
+	if archiveCompression, ok := ArchiveCompression(path); ok {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		return writeArchive(file, stream, compression, archiveCompression)
+	}
 
-	err = tarStreamTo(path, response.Body)
+	err := os.MkdirAll(path, 0755)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	return TarStreamTo(path, stream, compression)
+}
+
+// ArchiveCompression determines whether an output path names an archive
+// rather than a directory, and how the archive should be compressed.
+func ArchiveCompression(path string) (flaghelpers.CompressionFlag, bool) {
+	switch {
+	case strings.HasSuffix(path, ".tgz"), strings.HasSuffix(path, ".tar.gz"):
+		return flaghelpers.CompressionFlag{Encoding: flaghelpers.CompressionGzip}, true
+	case strings.HasSuffix(path, ".tar"):
+		return flaghelpers.CompressionFlag{Encoding: flaghelpers.CompressionNone}, true
+	default:
+		return flaghelpers.CompressionFlag{}, false
 	}
 }
+
+func writeArchive(dst io.Writer, src io.Reader, from flaghelpers.CompressionFlag, to flaghelpers.CompressionFlag) error {
+	if encodingOf(from) == encodingOf(to) {
+		_, err := io.Copy(dst, src)
+		return err
+	}
+
+	decompressed, err := compressedReader(from, src)
+	if err != nil {
+		return err
+	}
+
+	defer decompressed.Close()
+
+	compressor, err := compressedWriter(to, dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(compressor, decompressed)
+	if err != nil {
+		return err
+	}
+
+	return compressor.Close()
+}
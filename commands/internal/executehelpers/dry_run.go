@@ -0,0 +1,66 @@
+package executehelpers
+
+import (
+	"encoding/json"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+const dryRunPipeURL = "(created when executing)"
+
+type dryRunClient struct {
+	concourse.Client
+}
+
+// DryRunClient wraps client so that no pipes are created while determining
+// inputs and outputs. Everything else is passed through as-is.
+func DryRunClient(client concourse.Client) concourse.Client {
+	return dryRunClient{client}
+}
+
+func (dryRunClient) CreatePipe() (atc.Pipe, error) {
+	return atc.Pipe{
+		ReadURL:  dryRunPipeURL,
+		WriteURL: dryRunPipeURL,
+	}, nil
+}
+
+// RedactPlan converts plan to its generic JSON representation, with any
+// authorization embedded in step sources replaced.
+func RedactPlan(plan atc.Plan) (interface{}, error) {
+	payload, err := json.Marshal(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	var redacted interface{}
+	err = json.Unmarshal(payload, &redacted)
+	if err != nil {
+		return nil, err
+	}
+
+	redactAuthorization(redacted)
+
+	return redacted, nil
+}
+
+func redactAuthorization(node interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if source, ok := n["source"].(map[string]interface{}); ok {
+			if _, found := source["authorization"]; found {
+				source["authorization"] = "REDACTED"
+			}
+		}
+
+		for _, child := range n {
+			redactAuthorization(child)
+		}
+
+	case []interface{}:
+		for _, child := range n {
+			redactAuthorization(child)
+		}
+	}
+}
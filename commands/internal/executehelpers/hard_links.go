@@ -0,0 +1,22 @@
+// +build !windows
+
+package executehelpers
+
+import (
+	"os"
+	"syscall"
+)
+
+type hardLinkKey struct {
+	dev uint64
+	ino uint64
+}
+
+func hardLinkKeyOf(fi os.FileInfo) (hardLinkKey, bool) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return hardLinkKey{}, false
+	}
+
+	return hardLinkKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
@@ -0,0 +1,12 @@
+// +build windows
+
+package executehelpers
+
+import "os"
+
+type hardLinkKey struct{}
+
+// hard links are archived as regular files on Windows
+func hardLinkKeyOf(fi os.FileInfo) (hardLinkKey, bool) {
+	return hardLinkKey{}, false
+}
@@ -24,6 +24,7 @@ func DetermineInputs(
 	client concourse.Client,
 	taskInputs []atc.TaskInputConfig,
 	inputMappings []flaghelpers.InputPairFlag,
+	jobInputMappings map[string]string,
 	inputsFrom flaghelpers.JobFlag,
 ) ([]Input, error) {
 	err := CheckForUnknownInputMappings(inputMappings, taskInputs)
@@ -31,16 +32,17 @@ func DetermineInputs(
 		return nil, err
 	}
 
-	if len(inputMappings) == 0 && inputsFrom.PipelineName == "" && inputsFrom.JobName == "" {
-		wd, err := os.Getwd()
+	for name := range jobInputMappings {
+		if !TaskInputsContainsName(taskInputs, name) {
+			return nil, fmt.Errorf("unknown input `%s`", name)
+		}
+	}
+
+	if inputsFrom.PipelineName == "" && inputsFrom.JobName == "" {
+		inputMappings, err = defaultInputMappings(inputMappings)
 		if err != nil {
 			return nil, err
 		}
-
-		inputMappings = append(inputMappings, flaghelpers.InputPairFlag{
-			Name: filepath.Base(wd),
-			Path: wd,
-		})
 	}
 
 	inputsFromLocal, err := GenerateLocalInputs(client, inputMappings)
@@ -57,10 +59,17 @@ func DetermineInputs(
 	for _, taskInput := range taskInputs {
 		input, found := inputsFromLocal[taskInput.Name]
 		if !found {
-			input, found = inputsFromJob[taskInput.Name]
+			jobInputName := taskInput.Name
+			if mapped, ok := jobInputMappings[taskInput.Name]; ok {
+				jobInputName = mapped
+			}
+
+			input, found = inputsFromJob[jobInputName]
 			if !found {
 				return nil, fmt.Errorf("missing required input `%s`", taskInput.Name)
 			}
+
+			input.Name = taskInput.Name
 		}
 
 		inputs = append(inputs, input)
@@ -69,6 +78,65 @@ func DetermineInputs(
 	return inputs, nil
 }
 
+// DetermineLocalInputs resolves the task's inputs to local directories
+// alone, for running the task without a server.
+func DetermineLocalInputs(
+	taskInputs []atc.TaskInputConfig,
+	inputMappings []flaghelpers.InputPairFlag,
+) ([]Input, error) {
+	err := CheckForUnknownInputMappings(inputMappings, taskInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	inputMappings, err = defaultInputMappings(inputMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := []Input{}
+	for _, taskInput := range taskInputs {
+		found := false
+		for _, i := range inputMappings {
+			if i.Name == taskInput.Name {
+				inputs = append(inputs, Input{
+					Name: i.Name,
+					Path: i.Path,
+				})
+
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("missing required input `%s`", taskInput.Name)
+		}
+	}
+
+	return inputs, nil
+}
+
+// with no inputs given, the working directory is provided as the input named
+// after it
+func defaultInputMappings(inputMappings []flaghelpers.InputPairFlag) ([]flaghelpers.InputPairFlag, error) {
+	if len(inputMappings) > 0 {
+		return inputMappings, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	return []flaghelpers.InputPairFlag{
+		{
+			Name: filepath.Base(wd),
+			Path: wd,
+		},
+	}, nil
+}
+
 func CheckForUnknownInputMappings(inputMappings []flaghelpers.InputPairFlag, validInputs []atc.TaskInputConfig) error {
 	for _, inputMapping := range inputMappings {
 		if !TaskInputsContainsName(validInputs, inputMapping.Name) {
@@ -133,3 +201,29 @@ func FetchInputsFromJob(client concourse.Client, inputsFrom flaghelpers.JobFlag)
 
 	return kvMap, nil
 }
+
+func FetchImageFromJob(client concourse.Client, imageFrom flaghelpers.JobResourceFlag) (*Input, error) {
+	if imageFrom.PipelineName == "" && imageFrom.JobName == "" {
+		return nil, nil
+	}
+
+	buildInputs, found, err := client.BuildInputsForJob(imageFrom.PipelineName, imageFrom.JobName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, errors.New("build inputs not found")
+	}
+
+	for _, buildInput := range buildInputs {
+		if buildInput.Name == imageFrom.ResourceName || buildInput.Resource == imageFrom.ResourceName {
+			return &Input{
+				Name:       buildInput.Name,
+				BuildInput: buildInput,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("job '%s/%s' has no input for resource `%s`", imageFrom.PipelineName, imageFrom.JobName, imageFrom.ResourceName)
+}
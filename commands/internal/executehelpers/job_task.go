@@ -0,0 +1,71 @@
+package executehelpers
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+func FindJobTask(client concourse.Client, job flaghelpers.JobFlag, taskName string) (atc.PlanConfig, error) {
+	config, _, _, found, err := client.PipelineConfig(job.PipelineName)
+	if err != nil {
+		return atc.PlanConfig{}, err
+	}
+
+	if !found {
+		return atc.PlanConfig{}, fmt.Errorf("pipeline '%s' not found", job.PipelineName)
+	}
+
+	for _, jobConfig := range config.Jobs {
+		if jobConfig.Name != job.JobName {
+			continue
+		}
+
+		task, found := findTaskInPlan(jobConfig.Plan, taskName)
+		if !found {
+			return atc.PlanConfig{}, fmt.Errorf("job '%s/%s' has no task `%s`", job.PipelineName, job.JobName, taskName)
+		}
+
+		return task, nil
+	}
+
+	return atc.PlanConfig{}, fmt.Errorf("job '%s/%s' not found", job.PipelineName, job.JobName)
+}
+
+func findTaskInPlan(plan atc.PlanSequence, taskName string) (atc.PlanConfig, bool) {
+	for _, step := range plan {
+		if task, found := findTaskInStep(&step, taskName); found {
+			return task, true
+		}
+	}
+
+	return atc.PlanConfig{}, false
+}
+
+func findTaskInStep(step *atc.PlanConfig, taskName string) (atc.PlanConfig, bool) {
+	if step == nil {
+		return atc.PlanConfig{}, false
+	}
+
+	if step.Task == taskName {
+		return *step, true
+	}
+
+	for _, sequence := range []*atc.PlanSequence{step.Do, step.Aggregate} {
+		if sequence != nil {
+			if task, found := findTaskInPlan(*sequence, taskName); found {
+				return task, true
+			}
+		}
+	}
+
+	for _, hook := range []*atc.PlanConfig{step.Ensure, step.OnSuccess, step.OnFailure, step.Try} {
+		if task, found := findTaskInStep(hook, taskName); found {
+			return task, true
+		}
+	}
+
+	return atc.PlanConfig{}, false
+}
@@ -0,0 +1,301 @@
+package executehelpers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+)
+
+var errAborted = errors.New("aborted")
+
+var uncompressed = flaghelpers.CompressionFlag{Encoding: flaghelpers.CompressionNone}
+
+// LocalRun runs a task on this machine rather than on a worker. Its inputs
+// and outputs are laid out in a temporary directory as they would be in a
+// container, and it emits the same events as a one-off build, so it can be
+// rendered like one.
+//
+// There is no container: the task's image is ignored, and the process
+// inherits this environment, with the task's params set on top.
+type LocalRun struct {
+	config         atc.TaskConfig
+	inputs         []Input
+	outputs        []Output
+	excludeIgnored bool
+
+	events chan atc.Event
+
+	abort     chan struct{}
+	abortOnce sync.Once
+}
+
+func RunLocal(config atc.TaskConfig, inputs []Input, outputs []Output, excludeIgnored bool) *LocalRun {
+	run := &LocalRun{
+		config:         config,
+		inputs:         inputs,
+		outputs:        outputs,
+		excludeIgnored: excludeIgnored,
+
+		events: make(chan atc.Event, 100),
+		abort:  make(chan struct{}),
+	}
+
+	go run.run()
+
+	return run
+}
+
+func (run *LocalRun) NextEvent() (atc.Event, error) {
+	ev, ok := <-run.events
+	if !ok {
+		return nil, io.EOF
+	}
+
+	return ev, nil
+}
+
+func (run *LocalRun) Close() error {
+	run.Abort()
+	return nil
+}
+
+// Abort kills the task's process, if it is running, and skips any remaining
+// steps. The run then finishes with an aborted status.
+func (run *LocalRun) Abort() {
+	run.abortOnce.Do(func() {
+		close(run.abort)
+	})
+}
+
+func (run *LocalRun) run() {
+	defer close(run.events)
+
+	run.events <- event.Status{
+		Status: atc.StatusStarted,
+		Time:   time.Now().Unix(),
+	}
+
+	status := run.execute()
+
+	run.events <- event.Status{
+		Status: status,
+		Time:   time.Now().Unix(),
+	}
+}
+
+func (run *LocalRun) execute() atc.BuildStatus {
+	origin := event.Origin{
+		Name: "one-off",
+		Type: event.OriginTypeTask,
+	}
+
+	run.events <- event.InitializeTask{
+		TaskConfig: event.ShadowTaskConfig(run.config),
+		Origin:     origin,
+	}
+
+	workDir, err := ioutil.TempDir("", "fly-local")
+	if err != nil {
+		return run.errored(origin, err)
+	}
+
+	defer os.RemoveAll(workDir)
+
+	for _, input := range run.inputs {
+		err := run.copyInput(workDir, input)
+		if err != nil {
+			return run.errored(origin, fmt.Errorf("failed to copy input `%s`: %s", input.Name, err))
+		}
+	}
+
+	for _, output := range run.config.Outputs {
+		err := os.MkdirAll(artifactDir(workDir, output.Name, output.Path), 0755)
+		if err != nil {
+			return run.errored(origin, err)
+		}
+	}
+
+	exitStatus, err := run.runProcess(workDir, origin)
+	if err == errAborted {
+		return atc.StatusAborted
+	}
+
+	if err != nil {
+		return run.errored(origin, err)
+	}
+
+	run.events <- event.FinishTask{
+		Time:       time.Now().Unix(),
+		ExitStatus: exitStatus,
+		Origin:     origin,
+	}
+
+	for _, output := range run.outputs {
+		err := run.saveOutput(workDir, output)
+		if err != nil {
+			return run.errored(origin, fmt.Errorf("failed to save output `%s`: %s", output.Name, err))
+		}
+	}
+
+	if exitStatus != 0 {
+		return atc.StatusFailed
+	}
+
+	return atc.StatusSucceeded
+}
+
+func (run *LocalRun) errored(origin event.Origin, err error) atc.BuildStatus {
+	run.events <- event.Error{
+		Message: err.Error(),
+		Origin:  origin,
+	}
+
+	return atc.StatusErrored
+}
+
+func (run *LocalRun) runProcess(workDir string, origin event.Origin) (int, error) {
+	select {
+	case <-run.abort:
+		return 0, errAborted
+	default:
+	}
+
+	cmd := exec.Command(run.config.Run.Path, run.config.Run.Args...)
+	cmd.Dir = filepath.Join(workDir, filepath.FromSlash(run.config.Run.Dir))
+	cmd.Env = append(os.Environ(), paramsEnv(run.config.Params)...)
+	setProcessGroup(cmd)
+
+	stdoutOrigin := origin
+	stdoutOrigin.Source = event.OriginSourceStdout
+	cmd.Stdout = eventWriter{run.events, stdoutOrigin}
+
+	stderrOrigin := origin
+	stderrOrigin.Source = event.OriginSourceStderr
+	cmd.Stderr = eventWriter{run.events, stderrOrigin}
+
+	err := cmd.Start()
+	if err != nil {
+		return 0, err
+	}
+
+	run.events <- event.StartTask{
+		Time:   time.Now().Unix(),
+		Origin: origin,
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-exited:
+	case <-run.abort:
+		killProcessGroup(cmd)
+		<-exited
+		return 0, errAborted
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), nil
+		}
+	}
+
+	return 0, err
+}
+
+func (run *LocalRun) copyInput(workDir string, input Input) error {
+	configPath := ""
+	for _, configInput := range run.config.Inputs {
+		if configInput.Name == input.Name {
+			configPath = configInput.Path
+		}
+	}
+
+	files, err := inputFiles(input.Path, run.excludeIgnored)
+	if err != nil {
+		return err
+	}
+
+	archive, err := TarStreamFrom(input.Path, files, uncompressed)
+	if err != nil {
+		return err
+	}
+
+	defer archive.Close()
+
+	dest := artifactDir(workDir, input.Name, configPath)
+
+	err = os.MkdirAll(dest, 0755)
+	if err != nil {
+		return err
+	}
+
+	return TarStreamTo(dest, archive, uncompressed)
+}
+
+func (run *LocalRun) saveOutput(workDir string, output Output) error {
+	configPath := ""
+	for _, configOutput := range run.config.Outputs {
+		if configOutput.Name == output.Name {
+			configPath = configOutput.Path
+		}
+	}
+
+	archive, err := TarStreamFrom(artifactDir(workDir, output.Name, configPath), []string{"."}, uncompressed)
+	if err != nil {
+		return err
+	}
+
+	defer archive.Close()
+
+	return writeOutput(output.Path, archive, uncompressed)
+}
+
+// artifacts are placed at their configured path relative to the working
+// directory, or else at their name
+func artifactDir(workDir string, name string, path string) string {
+	if path == "" {
+		path = name
+	}
+
+	return filepath.Join(workDir, filepath.FromSlash(path))
+}
+
+func paramsEnv(params map[string]string) []string {
+	env := []string{}
+	for name, value := range params {
+		env = append(env, name+"="+value)
+	}
+
+	sort.Strings(env)
+
+	return env
+}
+
+type eventWriter struct {
+	events chan<- atc.Event
+	origin event.Origin
+}
+
+func (writer eventWriter) Write(p []byte) (int, error) {
+	writer.events <- event.Log{
+		Payload: string(p),
+		Origin:  writer.origin,
+	}
+
+	return len(p), nil
+}
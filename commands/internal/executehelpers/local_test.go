@@ -0,0 +1,196 @@
+package executehelpers_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	. "github.com/concourse/fly/commands/internal/executehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunLocal", func() {
+	var (
+		inputDir  string
+		outputDir string
+
+		config  atc.TaskConfig
+		inputs  []Input
+		outputs []Output
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the tasks here run /bin/sh")
+		}
+
+		var err error
+
+		inputDir, err = ioutil.TempDir("", "fly-local-input")
+		Expect(err).NotTo(HaveOccurred())
+
+		outputDir, err = ioutil.TempDir("", "fly-local-output")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(inputDir, "greeting"), []byte("hello"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		config = atc.TaskConfig{
+			Platform: "linux",
+			Inputs: []atc.TaskInputConfig{
+				{Name: "some-input", Path: "src/input"},
+			},
+			Outputs: []atc.TaskOutputConfig{
+				{Name: "some-output"},
+			},
+			Params: map[string]string{
+				"SUFFIX": "world",
+			},
+			Run: atc.TaskRunConfig{
+				Path: "/bin/sh",
+				Args: []string{"-c", `echo "$(cat src/input/greeting) $SUFFIX" | tee some-output/message; echo oops >&2`},
+			},
+		}
+
+		inputs = []Input{
+			{Name: "some-input", Path: inputDir},
+		}
+
+		outputs = []Output{
+			{Name: "some-output", Path: outputDir},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(inputDir)
+		os.RemoveAll(outputDir)
+	})
+
+	collectEvents := func(run *LocalRun) []atc.Event {
+		events := []atc.Event{}
+
+		for {
+			ev, err := run.NextEvent()
+			if err == io.EOF {
+				return events
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+
+			events = append(events, ev)
+		}
+	}
+
+	logsOf := func(events []atc.Event, source event.OriginSource) string {
+		logs := ""
+		for _, ev := range events {
+			if log, ok := ev.(event.Log); ok && log.Origin.Source == source {
+				logs += log.Payload
+			}
+		}
+
+		return logs
+	}
+
+	lastStatus := func(events []atc.Event) atc.BuildStatus {
+		status, ok := events[len(events)-1].(event.Status)
+		Expect(ok).To(BeTrue())
+
+		return status.Status
+	}
+
+	It("runs the task with its inputs, params and outputs laid out as in a container", func() {
+		events := collectEvents(RunLocal(config, inputs, outputs, false))
+
+		Expect(events[1]).To(BeAssignableToTypeOf(event.InitializeTask{}))
+		Expect(logsOf(events, event.OriginSourceStdout)).To(Equal("hello world\n"))
+		Expect(logsOf(events, event.OriginSourceStderr)).To(Equal("oops\n"))
+		Expect(lastStatus(events)).To(Equal(atc.StatusSucceeded))
+
+		message, err := ioutil.ReadFile(filepath.Join(outputDir, "message"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(message)).To(Equal("hello world\n"))
+	})
+
+	It("does not modify the local inputs", func() {
+		config.Run.Args = []string{"-c", "echo changed > src/input/greeting"}
+
+		collectEvents(RunLocal(config, inputs, outputs, false))
+
+		greeting, err := ioutil.ReadFile(filepath.Join(inputDir, "greeting"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(greeting)).To(Equal("hello"))
+	})
+
+	It("runs the task from its run dir", func() {
+		config.Run.Dir = "src/input"
+		config.Run.Args = []string{"-c", "cat greeting"}
+
+		events := collectEvents(RunLocal(config, inputs, nil, false))
+
+		Expect(logsOf(events, event.OriginSourceStdout)).To(Equal("hello"))
+	})
+
+	Context("when the task exits nonzero", func() {
+		BeforeEach(func() {
+			config.Run.Args = []string{"-c", "exit 3"}
+		})
+
+		It("finishes with the exit status and fails", func() {
+			events := collectEvents(RunLocal(config, inputs, outputs, false))
+
+			var finish event.FinishTask
+			for _, ev := range events {
+				if e, ok := ev.(event.FinishTask); ok {
+					finish = e
+				}
+			}
+
+			Expect(finish.ExitStatus).To(Equal(3))
+			Expect(lastStatus(events)).To(Equal(atc.StatusFailed))
+		})
+	})
+
+	Context("when the task cannot be started", func() {
+		BeforeEach(func() {
+			config.Run.Path = "/does/not/exist"
+		})
+
+		It("emits an error and errors", func() {
+			events := collectEvents(RunLocal(config, inputs, outputs, false))
+
+			Expect(events[len(events)-2]).To(BeAssignableToTypeOf(event.Error{}))
+			Expect(lastStatus(events)).To(Equal(atc.StatusErrored))
+		})
+	})
+
+	Context("when aborted", func() {
+		BeforeEach(func() {
+			config.Run.Args = []string{"-c", "echo started; sleep 60"}
+		})
+
+		It("kills the task and is aborted", func() {
+			run := RunLocal(config, inputs, outputs, false)
+
+			for {
+				ev, err := run.NextEvent()
+				Expect(err).NotTo(HaveOccurred())
+
+				if _, ok := ev.(event.Log); ok {
+					break
+				}
+			}
+
+			run.Abort()
+
+			events := collectEvents(run)
+			Expect(lastStatus(events)).To(Equal(atc.StatusAborted))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+package executehelpers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Matrix lists the values of each axis to run a task with; a build is run
+// for every combination of them. An axis that is left out does not vary.
+type Matrix struct {
+	Tags   [][]string          `yaml:"tags"`
+	Params []map[string]string `yaml:"params"`
+}
+
+type MatrixCombination struct {
+	Tags   []string
+	Params map[string]string
+}
+
+func LoadMatrix(path string) (Matrix, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Matrix{}, fmt.Errorf("failed to read matrix: %s", err)
+	}
+
+	var matrix Matrix
+	err = yaml.Unmarshal(contents, &matrix)
+	if err != nil {
+		return Matrix{}, fmt.Errorf("failed to parse matrix (%s): %s", path, err)
+	}
+
+	if len(matrix.Tags) == 0 && len(matrix.Params) == 0 {
+		return Matrix{}, errors.New("matrix has no axes; specify tags or params")
+	}
+
+	return matrix, nil
+}
+
+func (matrix Matrix) Combinations() []MatrixCombination {
+	tags := matrix.Tags
+	if len(tags) == 0 {
+		tags = [][]string{nil}
+	}
+
+	params := matrix.Params
+	if len(params) == 0 {
+		params = []map[string]string{nil}
+	}
+
+	combinations := []MatrixCombination{}
+	for _, t := range tags {
+		for _, p := range params {
+			combinations = append(combinations, MatrixCombination{
+				Tags:   t,
+				Params: p,
+			})
+		}
+	}
+
+	return combinations
+}
+
+// Name describes the combination by its tags and params, e.g.
+// "tags=linux,large FOO=bar".
+func (combination MatrixCombination) Name() string {
+	parts := []string{}
+
+	if len(combination.Tags) > 0 {
+		parts = append(parts, "tags="+strings.Join(combination.Tags, ","))
+	}
+
+	params := []string{}
+	for name, value := range combination.Params {
+		params = append(params, name+"="+value)
+	}
+
+	sort.Strings(params)
+
+	return strings.Join(append(parts, params...), " ")
+}
@@ -0,0 +1,98 @@
+package executehelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/concourse/fly/commands/internal/executehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Matrix", func() {
+	Describe("LoadMatrix", func() {
+		var tmpdir string
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-matrix")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		load := func(contents string) (Matrix, error) {
+			path := filepath.Join(tmpdir, "matrix.yml")
+
+			err := ioutil.WriteFile(path, []byte(contents), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			return LoadMatrix(path)
+		}
+
+		It("loads the axes", func() {
+			matrix, err := load(`---
+tags:
+- [linux]
+- [windows, large]
+params:
+- {GO_VERSION: "1.6"}
+`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matrix).To(Equal(Matrix{
+				Tags:   [][]string{{"linux"}, {"windows", "large"}},
+				Params: []map[string]string{{"GO_VERSION": "1.6"}},
+			}))
+		})
+
+		Context("when there are no axes", func() {
+			It("returns an error", func() {
+				_, err := load("---\n{}\n")
+				Expect(err).To(MatchError("matrix has no axes; specify tags or params"))
+			})
+		})
+	})
+
+	Describe("Combinations", func() {
+		It("combines every value of each axis", func() {
+			matrix := Matrix{
+				Tags:   [][]string{{"linux"}, {"windows"}},
+				Params: []map[string]string{{"A": "1"}, {"A": "2"}},
+			}
+
+			Expect(matrix.Combinations()).To(Equal([]MatrixCombination{
+				{Tags: []string{"linux"}, Params: map[string]string{"A": "1"}},
+				{Tags: []string{"linux"}, Params: map[string]string{"A": "2"}},
+				{Tags: []string{"windows"}, Params: map[string]string{"A": "1"}},
+				{Tags: []string{"windows"}, Params: map[string]string{"A": "2"}},
+			}))
+		})
+
+		It("does not vary an axis that is left out", func() {
+			matrix := Matrix{
+				Params: []map[string]string{{"A": "1"}, {"A": "2"}},
+			}
+
+			Expect(matrix.Combinations()).To(Equal([]MatrixCombination{
+				{Params: map[string]string{"A": "1"}},
+				{Params: map[string]string{"A": "2"}},
+			}))
+		})
+	})
+
+	Describe("MatrixCombination.Name", func() {
+		It("lists the tags and the params in order", func() {
+			combination := MatrixCombination{
+				Tags:   []string{"linux", "large"},
+				Params: map[string]string{"B": "2", "A": "1"},
+			}
+
+			Expect(combination.Name()).To(Equal("tags=linux,large A=1 B=2"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package executehelpers
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -20,8 +21,32 @@ func DetermineOutputs(
 	taskOutputs []atc.TaskOutputConfig,
 	outputMappings []flaghelpers.OutputPairFlag,
 ) ([]Output, error) {
+	outputs, err := DetermineLocalOutputs(taskOutputs, outputMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range outputs {
+		pipe, err := client.CreatePipe()
+		if err != nil {
+			return nil, err
+		}
+
+		outputs[i].Pipe = pipe
+	}
+
+	return outputs, nil
+}
+
+// DetermineLocalOutputs resolves the paths that the task's outputs are to be
+// written to, without creating pipes for them.
+func DetermineLocalOutputs(
+	taskOutputs []atc.TaskOutputConfig,
+	outputMappings []flaghelpers.OutputPairFlag,
+) ([]Output, error) {
 
 	outputs := []Output{}
+	streamingToStdout := false
 
 	for _, i := range outputMappings {
 		outputName := i.Name
@@ -36,20 +61,24 @@ func DetermineOutputs(
 			return nil, fmt.Errorf("unknown output '%s'", outputName)
 		}
 
-		absPath, err := filepath.Abs(i.Path)
-		if err != nil {
-			return nil, err
-		}
+		absPath := i.Path
+		if i.Path == StdoutPath {
+			if streamingToStdout {
+				return nil, errors.New("only one output may be written to stdout")
+			}
 
-		pipe, err := client.CreatePipe()
-		if err != nil {
-			return nil, err
+			streamingToStdout = true
+		} else {
+			var err error
+			absPath, err = filepath.Abs(i.Path)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		outputs = append(outputs, Output{
 			Name: outputName,
 			Path: absPath,
-			Pipe: pipe,
 		})
 	}
 
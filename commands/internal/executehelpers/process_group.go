@@ -0,0 +1,18 @@
+// +build !windows
+
+package executehelpers
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// the task runs in its own process group, so that anything it spawns is
+// killed along with it; otherwise a lingering child holds its output open
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
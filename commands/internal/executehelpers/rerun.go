@@ -0,0 +1,275 @@
+package executehelpers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/concourse/atc"
+)
+
+// JobRerunPlan builds a one-off plan that runs the job's steps as they are
+// configured in the pipeline, with each get fetching the version it fetched
+// in the build being rerun. Puts are left out, along with their hooks, as
+// they would create new versions of the pipeline's resources, unless
+// withPuts is set; the names of those left out are returned.
+func JobRerunPlan(config atc.Config, jobName string, versions map[string]atc.Version, withPuts bool) (atc.Plan, []string, error) {
+	for _, job := range config.Jobs {
+		if job.Name != jobName {
+			continue
+		}
+
+		planner := &rerunPlanner{
+			fact:     atc.NewPlanFactory(time.Now().Unix()),
+			config:   config,
+			versions: versions,
+			withPuts: withPuts,
+		}
+
+		plan, err := planner.sequence(job.Plan)
+		if err != nil {
+			return atc.Plan{}, nil, err
+		}
+
+		return plan, planner.leftOut, nil
+	}
+
+	return atc.Plan{}, nil, fmt.Errorf("job '%s' not found", jobName)
+}
+
+// PlanUsesPipes says whether any of the plan's inputs were uploaded, or its
+// outputs downloaded, by fly. The pipes they went through can only be used
+// once, so the plan cannot be run again.
+func PlanUsesPipes(plan atc.Plan) bool {
+	switch {
+	case plan.Get != nil:
+		return plan.Get.Type == "archive"
+	case plan.Put != nil:
+		return plan.Put.Type == "archive"
+	case plan.Do != nil:
+		return anyUsesPipes(*plan.Do)
+	case plan.Aggregate != nil:
+		return anyUsesPipes(*plan.Aggregate)
+	case plan.Ensure != nil:
+		return PlanUsesPipes(plan.Ensure.Step) || PlanUsesPipes(plan.Ensure.Next)
+	}
+
+	return false
+}
+
+func anyUsesPipes(plans []atc.Plan) bool {
+	for _, plan := range plans {
+		if PlanUsesPipes(plan) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type rerunPlanner struct {
+	fact     atc.PlanFactory
+	config   atc.Config
+	versions map[string]atc.Version
+	withPuts bool
+
+	// the puts that were left out
+	leftOut []string
+}
+
+func (planner *rerunPlanner) sequence(steps atc.PlanSequence) (atc.Plan, error) {
+	plans := atc.DoPlan{}
+	for _, step := range steps {
+		plan, planned, err := planner.step(step)
+		if err != nil {
+			return atc.Plan{}, err
+		}
+
+		if planned {
+			plans = append(plans, plan)
+		}
+	}
+
+	return planner.fact.NewPlan(plans), nil
+}
+
+// step plans the step and its hooks, unless it is a put that is left out.
+// The step's timeout and attempts are left out; a rerun tries it once, for
+// as long as it takes.
+func (planner *rerunPlanner) step(step atc.PlanConfig) (atc.Plan, bool, error) {
+	var plan atc.Plan
+	var err error
+
+	switch {
+	case step.Get != "":
+		plan, err = planner.get(step)
+
+	case step.Put != "":
+		if !planner.withPuts {
+			planner.leftOut = append(planner.leftOut, step.Put)
+			return atc.Plan{}, false, nil
+		}
+
+		plan, err = planner.put(step)
+
+	case step.Task != "":
+		plan = planner.fact.NewPlan(atc.TaskPlan{
+			Name:              step.Task,
+			Privileged:        step.Privileged,
+			Tags:              step.Tags,
+			ConfigPath:        step.TaskConfigPath,
+			Config:            step.TaskConfig,
+			Params:            step.Params,
+			InputMapping:      step.InputMapping,
+			OutputMapping:     step.OutputMapping,
+			ImageArtifactName: step.ImageArtifactName,
+		})
+
+	case step.Do != nil:
+		plan, err = planner.sequence(*step.Do)
+
+	case step.Aggregate != nil:
+		plans := atc.AggregatePlan{}
+		for _, s := range *step.Aggregate {
+			var p atc.Plan
+			var planned bool
+			p, planned, err = planner.step(s)
+			if err != nil {
+				break
+			}
+
+			if planned {
+				plans = append(plans, p)
+			}
+		}
+
+		plan = planner.fact.NewPlan(plans)
+
+	case step.Try != nil:
+		var tried atc.Plan
+		var planned bool
+		tried, planned, err = planner.step(*step.Try)
+		if err == nil && !planned {
+			return atc.Plan{}, false, nil
+		}
+
+		plan = planner.fact.NewPlan(atc.TryPlan{Step: tried})
+
+	default:
+		err = errors.New("the job has a step of a kind that cannot be rerun")
+	}
+	if err != nil {
+		return atc.Plan{}, false, err
+	}
+
+	if step.OnSuccess != nil {
+		next, planned, err := planner.step(*step.OnSuccess)
+		if err != nil {
+			return atc.Plan{}, false, err
+		}
+
+		if planned {
+			plan = planner.fact.NewPlan(atc.OnSuccessPlan{Step: plan, Next: next})
+		}
+	}
+
+	if step.OnFailure != nil {
+		next, planned, err := planner.step(*step.OnFailure)
+		if err != nil {
+			return atc.Plan{}, false, err
+		}
+
+		if planned {
+			plan = planner.fact.NewPlan(atc.OnFailurePlan{Step: plan, Next: next})
+		}
+	}
+
+	if step.Ensure != nil {
+		next, planned, err := planner.step(*step.Ensure)
+		if err != nil {
+			return atc.Plan{}, false, err
+		}
+
+		if planned {
+			plan = planner.fact.NewPlan(atc.EnsurePlan{Step: plan, Next: next})
+		}
+	}
+
+	return plan, true, nil
+}
+
+func (planner *rerunPlanner) get(step atc.PlanConfig) (atc.Plan, error) {
+	resource, err := planner.resource(step, step.Get)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	version, found := planner.versions[step.Get]
+	if !found {
+		return atc.Plan{}, fmt.Errorf("the build did not fetch input `%s`, so there is no version of it to rerun with", step.Get)
+	}
+
+	return planner.fact.NewPlan(atc.GetPlan{
+		Name:     step.Get,
+		Resource: resource.Name,
+		Type:     resource.Type,
+		Source:   resource.Source,
+		Params:   step.Params,
+		Version:  version,
+		Tags:     step.Tags,
+	}), nil
+}
+
+// put plans the put followed by the get of the version it created, as the
+// pipeline would
+func (planner *rerunPlanner) put(step atc.PlanConfig) (atc.Plan, error) {
+	resource, err := planner.resource(step, step.Put)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	return planner.fact.NewPlan(atc.OnSuccessPlan{
+		Step: planner.fact.NewPlan(atc.PutPlan{
+			Name:     step.Put,
+			Resource: resource.Name,
+			Type:     resource.Type,
+			Source:   resource.Source,
+			Params:   step.Params,
+			Tags:     step.Tags,
+		}),
+		Next: planner.fact.NewPlan(atc.DependentGetPlan{
+			Name:     step.Put,
+			Resource: resource.Name,
+			Type:     resource.Type,
+			Source:   resource.Source,
+			Params:   step.GetParams,
+			Tags:     step.Tags,
+		}),
+	}), nil
+}
+
+// resource finds the resource that a get or put step named name uses. Those
+// of custom types are not supported, as one-off builds cannot use the
+// pipeline's resource types.
+func (planner *rerunPlanner) resource(step atc.PlanConfig, name string) (atc.ResourceConfig, error) {
+	resourceName := step.Resource
+	if resourceName == "" {
+		resourceName = name
+	}
+
+	for _, resource := range planner.config.Resources {
+		if resource.Name != resourceName {
+			continue
+		}
+
+		for _, resourceType := range planner.config.ResourceTypes {
+			if resourceType.Name == resource.Type {
+				return atc.ResourceConfig{}, fmt.Errorf("resource `%s` is of custom type `%s`, which a rerun cannot use", resource.Name, resource.Type)
+			}
+		}
+
+		return resource, nil
+	}
+
+	return atc.ResourceConfig{}, fmt.Errorf("resource `%s` not found", resourceName)
+}
@@ -0,0 +1,185 @@
+package executehelpers_test
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/testhelpers"
+
+	. "github.com/concourse/fly/commands/internal/executehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rerunning", func() {
+	Describe("JobRerunPlan", func() {
+		var config atc.Config
+		var versions map[string]atc.Version
+
+		BeforeEach(func() {
+			config = atc.Config{
+				Resources: atc.ResourceConfigs{
+					{
+						Name:   "some-resource",
+						Type:   "git",
+						Source: atc.Source{"uri": "https://example.com"},
+					},
+					{
+						Name:   "some-output",
+						Type:   "s3",
+						Source: atc.Source{"bucket": "some-bucket"},
+					},
+				},
+				Jobs: atc.JobConfigs{
+					{
+						Name: "some-job",
+						Plan: atc.PlanSequence{
+							{Get: "some-input", Resource: "some-resource", Params: atc.Params{"depth": 1}},
+							{Task: "some-task", TaskConfigPath: "some-input/task.yml", Privileged: true},
+							{Put: "some-output", Params: atc.Params{"file": "out/*"}},
+						},
+					},
+				},
+			}
+
+			versions = map[string]atc.Version{
+				"some-input": {"ref": "abc123"},
+			}
+		})
+
+		It("runs the job's steps other than its puts, fetching the versions the build fetched", func() {
+			plan, leftOut, err := JobRerunPlan(config, "some-job", versions, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(leftOut).To(Equal([]string{"some-output"}))
+
+			fact := atc.NewPlanFactory(0)
+
+			Expect(plan).To(testhelpers.MatchPlan(fact.NewPlan(atc.DoPlan{
+				fact.NewPlan(atc.GetPlan{
+					Name:     "some-input",
+					Resource: "some-resource",
+					Type:     "git",
+					Source:   atc.Source{"uri": "https://example.com"},
+					Params:   atc.Params{"depth": 1},
+					Version:  atc.Version{"ref": "abc123"},
+				}),
+				fact.NewPlan(atc.TaskPlan{
+					Name:       "some-task",
+					ConfigPath: "some-input/task.yml",
+					Privileged: true,
+				}),
+			})))
+		})
+
+		Context("when a step's hook is a put", func() {
+			BeforeEach(func() {
+				config.Jobs[0].Plan[1].OnSuccess = &atc.PlanConfig{Put: "some-output"}
+			})
+
+			It("leaves the hook out too", func() {
+				plan, leftOut, err := JobRerunPlan(config, "some-job", versions, false)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(leftOut).To(Equal([]string{"some-output", "some-output"}))
+
+				Expect((*plan.Do)[1].OnSuccess).To(BeNil())
+				Expect((*plan.Do)[1].Task).NotTo(BeNil())
+			})
+		})
+
+		Context("with puts", func() {
+			It("runs the puts too, each followed by a get of what it created", func() {
+				plan, leftOut, err := JobRerunPlan(config, "some-job", versions, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(leftOut).To(BeEmpty())
+
+				fact := atc.NewPlanFactory(0)
+
+				Expect(plan).To(testhelpers.MatchPlan(fact.NewPlan(atc.DoPlan{
+					fact.NewPlan(atc.GetPlan{
+						Name:     "some-input",
+						Resource: "some-resource",
+						Type:     "git",
+						Source:   atc.Source{"uri": "https://example.com"},
+						Params:   atc.Params{"depth": 1},
+						Version:  atc.Version{"ref": "abc123"},
+					}),
+					fact.NewPlan(atc.TaskPlan{
+						Name:       "some-task",
+						ConfigPath: "some-input/task.yml",
+						Privileged: true,
+					}),
+					fact.NewPlan(atc.OnSuccessPlan{
+						Step: fact.NewPlan(atc.PutPlan{
+							Name:     "some-output",
+							Resource: "some-output",
+							Type:     "s3",
+							Source:   atc.Source{"bucket": "some-bucket"},
+							Params:   atc.Params{"file": "out/*"},
+						}),
+						Next: fact.NewPlan(atc.DependentGetPlan{
+							Name:     "some-output",
+							Resource: "some-output",
+							Type:     "s3",
+							Source:   atc.Source{"bucket": "some-bucket"},
+						}),
+					}),
+				})))
+			})
+		})
+
+		Context("when the build did not fetch one of the job's inputs", func() {
+			BeforeEach(func() {
+				versions = map[string]atc.Version{}
+			})
+
+			It("returns an error", func() {
+				_, _, err := JobRerunPlan(config, "some-job", versions, false)
+				Expect(err).To(MatchError("the build did not fetch input `some-input`, so there is no version of it to rerun with"))
+			})
+		})
+
+		Context("when a resource is of a custom type", func() {
+			BeforeEach(func() {
+				config.ResourceTypes = atc.ResourceTypes{
+					{Name: "s3", Type: "docker-image"},
+				}
+			})
+
+			It("returns an error", func() {
+				_, _, err := JobRerunPlan(config, "some-job", versions, true)
+				Expect(err).To(MatchError("resource `some-output` is of custom type `s3`, which a rerun cannot use"))
+			})
+		})
+
+		Context("when the job does not exist", func() {
+			It("returns an error", func() {
+				_, _, err := JobRerunPlan(config, "bogus-job", versions, false)
+				Expect(err).To(MatchError("job 'bogus-job' not found"))
+			})
+		})
+	})
+
+	Describe("PlanUsesPipes", func() {
+		fact := atc.NewPlanFactory(0)
+
+		It("is true when an input was uploaded by fly", func() {
+			Expect(PlanUsesPipes(fact.NewPlan(atc.DoPlan{
+				fact.NewPlan(atc.AggregatePlan{
+					fact.NewPlan(atc.GetPlan{Name: "some-input", Type: "archive"}),
+				}),
+				fact.NewPlan(atc.TaskPlan{Name: "one-off"}),
+			}))).To(BeTrue())
+		})
+
+		It("is false when the inputs all came from a job", func() {
+			Expect(PlanUsesPipes(fact.NewPlan(atc.DoPlan{
+				fact.NewPlan(atc.AggregatePlan{
+					fact.NewPlan(atc.GetPlan{Name: "some-input", Type: "git"}),
+				}),
+				fact.NewPlan(atc.TaskPlan{Name: "one-off"}),
+			}))).To(BeFalse())
+		})
+	})
+})
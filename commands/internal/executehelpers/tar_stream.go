@@ -1,69 +1,334 @@
-// +build !windows
-
 package executehelpers
 
 import (
-	"bytes"
-	"compress/gzip"
+	"archive/tar"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
-	"syscall"
+	"time"
 
-	"github.com/kr/tarutil"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
 )
 
-func tarStreamFrom(workDir string, paths []string) (io.ReadCloser, error) {
-	var archive io.ReadCloser
+// TarStreamFrom archives the given paths, relative to workDir, and returns
+// the compressed stream. Ownership is not recorded and modification times
+// are truncated to the second, so the same tree produces the same archive on
+// every platform.
+func TarStreamFrom(workDir string, paths []string, compression flaghelpers.CompressionFlag) (io.ReadCloser, error) {
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
 
-	if tarPath, err := exec.LookPath("tar"); err == nil {
-		tarCmd := exec.Command(tarPath, "-czf", "-", "--null", "-T", "-")
-		tarCmd.Dir = workDir
-		tarCmd.Stderr = os.Stderr
+	compressor, err := compressedWriter(compression, w)
+	if err != nil {
+		return nil, err
+	}
 
-		tarCmd.Stdin = bytes.NewBufferString(strings.Join(paths, "\x00"))
+	go func() {
+		tw := tar.NewWriter(compressor)
+		links := map[hardLinkKey]string{}
 
-		tarCmd.SysProcAttr = &syscall.SysProcAttr{
-			Setpgid: true,
-		}
+		err := func() error {
+			for _, p := range paths {
+				err := writePathToTar(tw, links, absWorkDir, filepath.Join(absWorkDir, p))
+				if err != nil {
+					return err
+				}
+			}
+
+			err := tw.Close()
+			if err != nil {
+				return err
+			}
 
-		archive, err = tarCmd.StdoutPipe()
+			return compressor.Close()
+		}()
+
+		w.CloseWithError(err)
+	}()
+
+	return r, nil
+}
+
+func writePathToTar(tw *tar.Writer, links map[hardLinkKey]string, workDir string, srcPath string) error {
+	return filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return nil, fmt.Errorf("could not create tar pipe: %s", err)
+			return err
 		}
 
-		err = tarCmd.Start()
+		relative, err := filepath.Rel(workDir, path)
 		if err != nil {
-			return nil, fmt.Errorf("could not run tar: %s", err)
+			return err
+		}
+
+		return addTarFile(tw, links, path, relative, info)
+	})
+}
+
+func addTarFile(tw *tar.Writer, links map[hardLinkKey]string, path, name string, fi os.FileInfo) error {
+	link := ""
+	if fi.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
 		}
+	}
+
+	hdr, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() && !os.IsPathSeparator(name[len(name)-1]) {
+		name = name + "/"
+	}
+
+	if hdr.Typeflag == tar.TypeReg && name == "." {
+		// archiving a single file
+		hdr.Name = filepath.ToSlash(filepath.Base(path))
 	} else {
-		return nativeTarGZStreamFrom(workDir, paths)
+		hdr.Name = filepath.ToSlash(name)
 	}
 
-	return archive, nil
+	hdr.Uid = 0
+	hdr.Gid = 0
+	hdr.Uname = ""
+	hdr.Gname = ""
+	hdr.ModTime = hdr.ModTime.Truncate(time.Second)
+	hdr.AccessTime = time.Time{}
+	hdr.ChangeTime = time.Time{}
+
+	if hdr.Typeflag == tar.TypeReg {
+		if key, ok := hardLinkKeyOf(fi); ok {
+			if first, found := links[key]; found {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = first
+				hdr.Size = 0
+			} else {
+				links[key] = hdr.Name
+			}
+		}
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeReg {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func tarStreamTo(workDir string, stream io.Reader) error {
-	if tarPath, err := exec.LookPath("tar"); err == nil {
-		tarCmd := exec.Command(tarPath, "-xzf", "-")
-		tarCmd.Dir = workDir
-		tarCmd.Stderr = os.Stderr
+// TarStreamTo extracts the compressed archive into workDir. Entries that
+// would land outside of workDir, either directly or by way of a symlink
+// extracted earlier, are rejected.
+func TarStreamTo(workDir string, stream io.Reader, compression flaghelpers.CompressionFlag) error {
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return err
+	}
 
-		tarCmd.Stdin = stream
+	decompressed, err := compressedReader(compression, stream)
+	if err != nil {
+		return err
+	}
 
-		tarCmd.SysProcAttr = &syscall.SysProcAttr{
-			Setpgid: true,
+	defer decompressed.Close()
+
+	tr := tar.NewReader(decompressed)
+
+	// directory modes and times are applied last, as extracting their
+	// contents would otherwise clobber them
+	var dirs []*tar.Header
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		target, err := extractionPath(absWorkDir, hdr.Name)
+		if err != nil {
+			return err
 		}
 
-		return tarCmd.Run()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = replaceWith(target, func() error {
+				return os.MkdirAll(target, 0755)
+			})
+			if err != nil {
+				return err
+			}
+
+			dirs = append(dirs, hdr)
+
+		case tar.TypeReg:
+			err = extractFile(target, hdr, tr)
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			err = replaceWith(target, func() error {
+				return os.Symlink(hdr.Linkname, target)
+			})
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeLink:
+			source, err := extractionPath(absWorkDir, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+
+			err = replaceWith(target, func() error {
+				return os.Link(source, target)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target, err := extractionPath(absWorkDir, dirs[i].Name)
+		if err != nil {
+			return err
+		}
+
+		err = os.Chmod(target, dirs[i].FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		err = os.Chtimes(target, dirs[i].ModTime, dirs[i].ModTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractionPath(workDir string, name string) (string, error) {
+	target := filepath.Join(workDir, filepath.FromSlash(name))
+
+	if !withinDir(workDir, target) {
+		return "", fmt.Errorf("refusing to extract '%s' outside of destination", name)
+	}
+
+	if target == workDir {
+		return target, nil
+	}
+
+	// directories that don't exist yet are created from the deepest one that
+	// does, so that is where a symlink extracted earlier could lead outside
+	existing := filepath.Dir(target)
+	for {
+		_, err := os.Lstat(existing)
+		if err == nil {
+			break
+		}
+
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		existing = filepath.Dir(existing)
+	}
+
+	parent, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+
+	realWorkDir, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return "", err
+	}
+
+	if !withinDir(realWorkDir, parent) {
+		return "", fmt.Errorf("refusing to extract '%s' through a symlink outside of destination", name)
 	}
 
-	gr, err := gzip.NewReader(stream)
+	return target, nil
+}
+
+func withinDir(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func extractFile(target string, hdr *tar.Header, tr io.Reader) error {
+	mode := hdr.FileInfo().Mode().Perm()
+
+	return replaceWith(target, func() error {
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(file, tr)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+
+		// the mode given to OpenFile is subject to umask
+		err = os.Chmod(target, mode)
+		if err != nil {
+			return err
+		}
+
+		return os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+	})
+}
+
+func replaceWith(target string, create func() error) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
 	if err != nil {
 		return err
 	}
 
-	return tarutil.ExtractAll(gr, workDir, tarutil.Chmod|tarutil.Chtimes|tarutil.Symlink)
+	fi, err := os.Lstat(target)
+	if err == nil && !fi.IsDir() {
+		err = os.Remove(target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return create()
 }
@@ -0,0 +1,364 @@
+package executehelpers_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	. "github.com/concourse/fly/commands/internal/executehelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TarStream", func() {
+	var (
+		srcDir  string
+		destDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		srcDir, err = ioutil.TempDir("", "fly-tar-src")
+		Expect(err).NotTo(HaveOccurred())
+
+		destDir, err = ioutil.TempDir("", "fly-tar-dest")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir)
+		os.RemoveAll(destDir)
+	})
+
+	roundTrip := func(compression flaghelpers.CompressionFlag) {
+		archive, err := TarStreamFrom(srcDir, []string{"."}, compression)
+		Expect(err).NotTo(HaveOccurred())
+
+		defer archive.Close()
+
+		err = TarStreamTo(destDir, archive, compression)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	for _, c := range []flaghelpers.CompressionFlag{
+		{Encoding: "none"},
+		{Encoding: "gzip"},
+		{Encoding: "gzip", Level: 9},
+	} {
+		compression := c
+
+		Context("with "+compression.String()+" compression", func() {
+			It("round-trips randomly generated trees", func() {
+				for seed := int64(0); seed < 20; seed++ {
+					os.RemoveAll(srcDir)
+					os.RemoveAll(destDir)
+					Expect(os.Mkdir(srcDir, 0755)).To(Succeed())
+					Expect(os.Mkdir(destDir, 0755)).To(Succeed())
+
+					generateTree(srcDir, rand.New(rand.NewSource(seed)))
+
+					roundTrip(compression)
+
+					Expect(snapshotTree(destDir)).To(Equal(snapshotTree(srcDir)), fmt.Sprintf("seed %d", seed))
+				}
+			})
+		})
+	}
+
+	It("archives only the given paths", func() {
+		Expect(os.MkdirAll(filepath.Join(srcDir, "some", "dir"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(srcDir, "some", "dir", "file"), []byte("included"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(srcDir, "ignored"), []byte("excluded"), 0644)).To(Succeed())
+
+		archive, err := TarStreamFrom(srcDir, []string{"some/dir/file"}, flaghelpers.CompressionFlag{})
+		Expect(err).NotTo(HaveOccurred())
+
+		defer archive.Close()
+
+		err = TarStreamTo(destDir, archive, flaghelpers.CompressionFlag{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.ReadFile(filepath.Join(destDir, "some", "dir", "file"))).To(Equal([]byte("included")))
+		Expect(filepath.Join(destDir, "ignored")).NotTo(BeAnExistingFile())
+	})
+
+	if runtime.GOOS != "windows" {
+		Context("compared to the system tar", func() {
+			var tarPath string
+
+			BeforeEach(func() {
+				var err error
+				tarPath, err = exec.LookPath("tar")
+				if err != nil {
+					Skip("tar is not installed")
+				}
+
+				generateTree(srcDir, rand.New(rand.NewSource(time.Now().UnixNano())))
+			})
+
+			It("produces archives that it extracts identically", func() {
+				archive, err := TarStreamFrom(srcDir, []string{"."}, flaghelpers.CompressionFlag{Encoding: "none"})
+				Expect(err).NotTo(HaveOccurred())
+
+				defer archive.Close()
+
+				tarCmd := exec.Command(tarPath, "-xf", "-")
+				tarCmd.Dir = destDir
+				tarCmd.Stdin = archive
+
+				output, err := tarCmd.CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+
+				Expect(snapshotTree(destDir)).To(Equal(snapshotTree(srcDir)))
+			})
+
+			It("extracts its archives identically", func() {
+				tarCmd := exec.Command(tarPath, "-cf", "-", ".")
+				tarCmd.Dir = srcDir
+
+				archive, err := tarCmd.Output()
+				Expect(err).NotTo(HaveOccurred())
+
+				err = TarStreamTo(destDir, bytes.NewReader(archive), flaghelpers.CompressionFlag{Encoding: "none"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(snapshotTree(destDir)).To(Equal(snapshotTree(srcDir)))
+			})
+		})
+	}
+
+	Describe("extracting untrusted archives", func() {
+		var outsideDir string
+
+		BeforeEach(func() {
+			var err error
+			outsideDir, err = ioutil.TempDir("", "fly-tar-outside")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outsideDir)
+		})
+
+		extract := func(headers ...*tar.Header) error {
+			buf := new(bytes.Buffer)
+			tw := tar.NewWriter(buf)
+
+			for _, hdr := range headers {
+				Expect(tw.WriteHeader(hdr)).To(Succeed())
+			}
+
+			Expect(tw.Close()).To(Succeed())
+
+			return TarStreamTo(destDir, buf, flaghelpers.CompressionFlag{Encoding: "none"})
+		}
+
+		It("refuses entries that climb out of the destination", func() {
+			err := extract(&tar.Header{
+				Name:     "../" + filepath.Base(outsideDir) + "/evil",
+				Typeflag: tar.TypeReg,
+				Mode:     0644,
+			})
+			Expect(err).To(MatchError(ContainSubstring("outside of destination")))
+
+			Expect(filepath.Join(outsideDir, "evil")).NotTo(BeAnExistingFile())
+		})
+
+		It("refuses hard links to files outside of the destination", func() {
+			err := extract(&tar.Header{
+				Name:     "evil",
+				Typeflag: tar.TypeLink,
+				Linkname: "../" + filepath.Base(outsideDir),
+			})
+			Expect(err).To(MatchError(ContainSubstring("outside of destination")))
+		})
+
+		if runtime.GOOS != "windows" {
+			It("refuses entries that are written through a symlink", func() {
+				err := extract(
+					&tar.Header{
+						Name:     "escape",
+						Typeflag: tar.TypeSymlink,
+						Linkname: outsideDir,
+					},
+					&tar.Header{
+						Name:     "escape/evil",
+						Typeflag: tar.TypeReg,
+						Mode:     0644,
+					},
+				)
+				Expect(err).To(MatchError(ContainSubstring("through a symlink")))
+
+				Expect(filepath.Join(outsideDir, "evil")).NotTo(BeAnExistingFile())
+			})
+
+			It("refuses entries that are written through a symlink into directories that don't exist yet", func() {
+				err := extract(
+					&tar.Header{
+						Name:     "escape",
+						Typeflag: tar.TypeSymlink,
+						Linkname: outsideDir,
+					},
+					&tar.Header{
+						Name:     "escape/newdir/evil",
+						Typeflag: tar.TypeReg,
+						Mode:     0644,
+					},
+				)
+				Expect(err).To(MatchError(ContainSubstring("through a symlink")))
+
+				Expect(filepath.Join(outsideDir, "newdir")).NotTo(BeADirectory())
+			})
+		}
+	})
+})
+
+type treeEntry struct {
+	Type     string
+	Mode     os.FileMode
+	ModTime  int64
+	Content  string
+	Link     string
+	LinkedTo string
+}
+
+func generateTree(dir string, rng *rand.Rand) {
+	dirs := []string{"."}
+	files := []string{}
+
+	modes := []os.FileMode{0600, 0644, 0700, 0755}
+
+	count := 5 + rng.Intn(20)
+	for i := 0; i < count; i++ {
+		parent := dirs[rng.Intn(len(dirs))]
+		name := filepath.Join(parent, fmt.Sprintf("entry-%d", i))
+
+		switch kind := rng.Intn(6); {
+		case kind == 0:
+			Expect(os.Mkdir(filepath.Join(dir, name), modes[rng.Intn(len(modes))]|0700)).To(Succeed())
+			dirs = append(dirs, name)
+
+		case kind == 1:
+			// well past the 100 byte name field of a plain ustar header
+			long := filepath.Join(parent, strings.Repeat(fmt.Sprintf("long-segment-%d-", i), 4), strings.Repeat("x", 90))
+			Expect(os.MkdirAll(filepath.Join(dir, filepath.Dir(long)), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, long), []byte(long), 0644)).To(Succeed())
+			dirs = append(dirs, filepath.Dir(long))
+			files = append(files, long)
+
+		case kind == 2 && runtime.GOOS != "windows":
+			target := fmt.Sprintf("dangling-%d", i)
+			if len(files) > 0 {
+				var err error
+				target, err = filepath.Rel(parent, files[rng.Intn(len(files))])
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(os.Symlink(target, filepath.Join(dir, name))).To(Succeed())
+
+		case kind == 3 && runtime.GOOS != "windows" && len(files) > 0:
+			Expect(os.Link(filepath.Join(dir, files[rng.Intn(len(files))]), filepath.Join(dir, name))).To(Succeed())
+
+		default:
+			content := make([]byte, rng.Intn(64*1024))
+			rng.Read(content)
+
+			Expect(ioutil.WriteFile(filepath.Join(dir, name), content, 0600)).To(Succeed())
+			Expect(os.Chmod(filepath.Join(dir, name), modes[rng.Intn(len(modes))])).To(Succeed())
+			files = append(files, name)
+		}
+	}
+
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	// children are walked after their parents, so set times in reverse to
+	// avoid clobbering the parents' times
+	for i := len(paths) - 1; i >= 0; i-- {
+		mtime := time.Unix(1000000000+rng.Int63n(500000000), 0)
+		Expect(os.Chtimes(paths[i], mtime, mtime)).To(Succeed())
+	}
+}
+
+func snapshotTree(dir string) map[string]treeEntry {
+	snapshot := map[string]treeEntry{}
+	seen := map[string]os.FileInfo{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		entry := treeEntry{Mode: info.Mode().Perm()}
+
+		switch {
+		case info.IsDir():
+			entry.Type = "dir"
+			entry.ModTime = info.ModTime().Unix()
+
+		case info.Mode()&os.ModeSymlink != 0:
+			entry.Type = "symlink"
+			entry.Mode = 0
+
+			entry.Link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+		default:
+			entry.Type = "file"
+			entry.ModTime = info.ModTime().Unix()
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			entry.Content = string(content)
+
+			for other, otherInfo := range seen {
+				if os.SameFile(info, otherInfo) {
+					entry.LinkedTo = other
+				}
+			}
+
+			if entry.LinkedTo == "" {
+				seen[relative] = info
+			}
+		}
+
+		snapshot[relative] = entry
+
+		return nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return snapshot
+}
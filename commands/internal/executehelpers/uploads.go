@@ -4,38 +4,77 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
 
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/go-concourse/concourse"
 )
 
-func Upload(client concourse.Client, input Input, excludeIgnored bool) {
-	path := input.Path
-	pipe := input.Pipe
+func Upload(client concourse.Client, input Input, excludeIgnored bool, compression flaghelpers.CompressionFlag) error {
+	files, err := inputFiles(input.Path, excludeIgnored)
+	if err != nil {
+		return fmt.Errorf("could not determine ignored files: %s", err)
+	}
+
+	archive, err := TarStreamFrom(input.Path, files, compression)
+	if err != nil {
+		return fmt.Errorf("could not create tar stream: %s", err)
+	}
 
-	var files []string
-	var err error
+	defer archive.Close()
 
-	if excludeIgnored {
-		files, err = getGitFiles(path)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "could not determine ignored files:", err)
-			return
-		}
-	} else {
-		files = []string{"."}
+	return uploadStream(client, input.Pipe, archive)
+}
+
+// ArchiveInput archives an input to a temporary file, so that the same
+// archive can be uploaded to more than one build with UploadArchive. The
+// caller removes the file.
+func ArchiveInput(input Input, excludeIgnored bool, compression flaghelpers.CompressionFlag) (string, error) {
+	files, err := inputFiles(input.Path, excludeIgnored)
+	if err != nil {
+		return "", fmt.Errorf("could not determine ignored files: %s", err)
+	}
+
+	archive, err := TarStreamFrom(input.Path, files, compression)
+	if err != nil {
+		return "", err
+	}
+
+	defer archive.Close()
+
+	file, err := ioutil.TempFile("", "fly-input")
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(file, archive)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
 	}
 
-	archive, err := tarStreamFrom(path, files)
+	return file.Name(), nil
+}
+
+func UploadArchive(client concourse.Client, input Input, archivePath string) error {
+	archive, err := os.Open(archivePath)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "could create tar stream:", err)
-		return
+		return fmt.Errorf("could not open archive: %s", err)
 	}
 
 	defer archive.Close()
 
+	return uploadStream(client, input.Pipe, archive)
+}
+
+func uploadStream(client concourse.Client, pipe atc.Pipe, archive io.Reader) error {
 	upload, err := http.NewRequest("PUT", pipe.WriteURL, archive)
 	if err != nil {
 		panic(err)
@@ -43,15 +82,24 @@ func Upload(client concourse.Client, input Input, excludeIgnored bool) {
 
 	response, err := client.HTTPClient().Do(upload)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "upload request failed:", err)
-		return
+		return fmt.Errorf("upload request failed: %s", err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		fmt.Fprintln(os.Stderr, badResponseError("uploading bits", response))
+		return badResponseError("uploading bits", response)
+	}
+
+	return nil
+}
+
+func inputFiles(dir string, excludeIgnored bool) ([]string, error) {
+	if excludeIgnored {
+		return getGitFiles(dir)
 	}
+
+	return []string{"."}, nil
 }
 
 func getGitFiles(dir string) ([]string, error) {
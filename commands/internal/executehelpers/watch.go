@@ -0,0 +1,170 @@
+package executehelpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const watchPollInterval = 250 * time.Millisecond
+
+// WatchForChanges polls the given paths, notifying on the returned channel
+// once changes to them have settled for the debounce period. When
+// excludeIgnored is set, only the files that Upload would send are watched.
+// Anything at or under the excluded paths, such as where outputs are
+// downloaded to, is not watched.
+func WatchForChanges(paths []string, excluded []string, excludeIgnored bool, debounce time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	exclusions := absPaths(excluded)
+
+	go func() {
+		last := snapshotPaths(paths, exclusions, excludeIgnored)
+
+		settling := false
+		var lastChange time.Time
+
+		for range time.Tick(watchPollInterval) {
+			current := snapshotPaths(paths, exclusions, excludeIgnored)
+
+			if !sameSnapshot(last, current) {
+				last = current
+				settling = true
+				lastChange = time.Now()
+				continue
+			}
+
+			if settling && time.Since(lastChange) >= debounce {
+				settling = false
+
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+type fileStamp struct {
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{
+		size:    info.Size(),
+		mode:    info.Mode(),
+		modTime: info.ModTime(),
+	}
+}
+
+func snapshotPaths(paths []string, exclusions []string, excludeIgnored bool) map[string]fileStamp {
+	snapshot := map[string]fileStamp{}
+
+	for _, path := range paths {
+		if isExcluded(path, exclusions) {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			snapshot[path] = stampOf(info)
+			continue
+		}
+
+		if excludeIgnored {
+			files, err := getGitFiles(path)
+			if err == nil {
+				for _, file := range files {
+					filePath := filepath.Join(path, file)
+					if isExcluded(filePath, exclusions) {
+						continue
+					}
+
+					info, err := os.Lstat(filePath)
+					if err == nil {
+						snapshot[filePath] = stampOf(info)
+					}
+				}
+
+				continue
+			}
+		}
+
+		filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			// git touches its own bookkeeping on every status; that's not a change
+			if info.IsDir() && info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			if isExcluded(filePath, exclusions) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			snapshot[filePath] = stampOf(info)
+
+			return nil
+		})
+	}
+
+	return snapshot
+}
+
+func absPaths(paths []string) []string {
+	abs := []string{}
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err == nil {
+			abs = append(abs, absPath)
+		}
+	}
+
+	return abs
+}
+
+func isExcluded(path string, exclusions []string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	for _, exclusion := range exclusions {
+		if absPath == exclusion || strings.HasPrefix(absPath, exclusion+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, stamp := range a {
+		other, found := b[path]
+		if !found || other.size != stamp.size || other.mode != stamp.mode || !other.modTime.Equal(stamp.modTime) {
+			return false
+		}
+	}
+
+	return true
+}
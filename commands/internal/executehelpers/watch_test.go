@@ -0,0 +1,52 @@
+package executehelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/concourse/fly/commands/internal/executehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WatchForChanges", func() {
+	var dir string
+	var outputDir string
+	var changes <-chan struct{}
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "fly-watch")
+		Expect(err).NotTo(HaveOccurred())
+
+		outputDir = filepath.Join(dir, "output")
+		Expect(os.Mkdir(outputDir, 0755)).To(Succeed())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "input"), []byte("one"), 0644)).To(Succeed())
+
+		changes = WatchForChanges([]string{dir}, []string{outputDir}, false, 10*time.Millisecond)
+
+		// let the first snapshot be taken
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("notifies when a watched file changes", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "input"), []byte("two!"), 0644)).To(Succeed())
+
+		Eventually(changes, 2*time.Second).Should(Receive())
+	})
+
+	It("does not notify when an excluded path changes", func() {
+		Expect(ioutil.WriteFile(filepath.Join(outputDir, "downloaded"), []byte("output"), 0644)).To(Succeed())
+
+		Consistently(changes, time.Second).ShouldNot(Receive())
+	})
+})
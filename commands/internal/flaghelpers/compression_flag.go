@@ -0,0 +1,62 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+type CompressionFlag struct {
+	Encoding string
+	Level    int
+}
+
+func (compression *CompressionFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, ":", 2)
+
+	encoding := vs[0]
+
+	var min, max int
+	switch encoding {
+	case CompressionNone:
+		if len(vs) == 2 {
+			return fmt.Errorf("compression '%s' does not take a level", encoding)
+		}
+	case CompressionGzip:
+		min, max = 1, 9
+	default:
+		return fmt.Errorf("unknown compression '%s' (must be none or gzip[:LEVEL])", encoding)
+	}
+
+	level := 0
+	if len(vs) == 2 {
+		var err error
+		level, err = strconv.Atoi(vs[1])
+		if err != nil || level < min || level > max {
+			return fmt.Errorf("invalid %s compression level '%s' (must be %d-%d)", encoding, vs[1], min, max)
+		}
+	}
+
+	compression.Encoding = encoding
+	compression.Level = level
+
+	return nil
+}
+
+func (compression CompressionFlag) String() string {
+	encoding := compression.Encoding
+	if encoding == "" {
+		encoding = CompressionGzip
+	}
+
+	if compression.Level == 0 {
+		return encoding
+	}
+
+	return fmt.Sprintf("%s:%d", encoding, compression.Level)
+}
@@ -0,0 +1,57 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompressionFlag", func() {
+	var compressionFlag *CompressionFlag
+
+	BeforeEach(func() {
+		compressionFlag = &CompressionFlag{}
+	})
+
+	It("parses an encoding without a level", func() {
+		err := compressionFlag.UnmarshalFlag("none")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*compressionFlag).To(Equal(CompressionFlag{Encoding: "none"}))
+		Expect(compressionFlag.String()).To(Equal("none"))
+	})
+
+	It("parses an encoding with a level", func() {
+		err := compressionFlag.UnmarshalFlag("gzip:9")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*compressionFlag).To(Equal(CompressionFlag{Encoding: "gzip", Level: 9}))
+		Expect(compressionFlag.String()).To(Equal("gzip:9"))
+	})
+
+	It("defaults to gzip when unset", func() {
+		Expect(compressionFlag.String()).To(Equal("gzip"))
+	})
+
+	Context("when the encoding is unknown", func() {
+		It("displays an error message", func() {
+			err := compressionFlag.UnmarshalFlag("bzip2")
+			Expect(err).To(MatchError("unknown compression 'bzip2' (must be none or gzip[:LEVEL])"))
+		})
+	})
+
+	Context("when the level is out of range", func() {
+		It("displays an error message", func() {
+			err := compressionFlag.UnmarshalFlag("gzip:10")
+			Expect(err).To(MatchError("invalid gzip compression level '10' (must be 1-9)"))
+		})
+	})
+
+	Context("when a level is given for none", func() {
+		It("displays an error message", func() {
+			err := compressionFlag.UnmarshalFlag("none:1")
+			Expect(err).To(MatchError("compression 'none' does not take a level"))
+		})
+	})
+})
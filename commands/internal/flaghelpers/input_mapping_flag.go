@@ -0,0 +1,23 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+type InputMappingFlag struct {
+	Name       string
+	MappedName string
+}
+
+func (mapping *InputMappingFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "=", 2)
+	if len(vs) != 2 || vs[0] == "" || vs[1] == "" {
+		return fmt.Errorf("invalid input mapping '%s' (must be task-input=job-input)", value)
+	}
+
+	mapping.Name = vs[0]
+	mapping.MappedName = vs[1]
+
+	return nil
+}
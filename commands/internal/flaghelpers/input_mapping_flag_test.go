@@ -0,0 +1,31 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InputMappingFlag", func() {
+	It("parses the task input and job input names", func() {
+		mapping := &InputMappingFlag{}
+
+		err := mapping.UnmarshalFlag("some-input=some-resource")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*mapping).To(Equal(InputMappingFlag{
+			Name:       "some-input",
+			MappedName: "some-resource",
+		}))
+	})
+
+	Context("when either name is missing", func() {
+		It("displays an error message", func() {
+			mapping := &InputMappingFlag{}
+
+			err := mapping.UnmarshalFlag("some-input=")
+			Expect(err).To(MatchError("invalid input mapping 'some-input=' (must be task-input=job-input)"))
+		})
+	})
+})
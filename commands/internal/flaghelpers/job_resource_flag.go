@@ -0,0 +1,37 @@
+package flaghelpers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type JobResourceFlag struct {
+	PipelineName string
+	JobName      string
+	ResourceName string
+}
+
+func (flag *JobResourceFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, ":", 2)
+	if len(vs) != 2 {
+		return errors.New("argument format should be <pipeline>/<job>:<resource>")
+	}
+
+	var job JobFlag
+	err := job.UnmarshalFlag(vs[0])
+	if err != nil {
+		return err
+	}
+
+	if vs[1] == "" {
+		return concourse.NameRequiredError("resource")
+	}
+
+	flag.PipelineName = job.PipelineName
+	flag.JobName = job.JobName
+	flag.ResourceName = vs[1]
+
+	return nil
+}
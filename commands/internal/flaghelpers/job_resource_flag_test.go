@@ -0,0 +1,41 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JobResourceFlag", func() {
+	It("parses the pipeline, job and resource", func() {
+		flag := &JobResourceFlag{}
+
+		err := flag.UnmarshalFlag("some-pipeline/some-job:some-resource")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*flag).To(Equal(JobResourceFlag{
+			PipelineName: "some-pipeline",
+			JobName:      "some-job",
+			ResourceName: "some-resource",
+		}))
+	})
+
+	Context("when there is no resource specified", func() {
+		It("displays an error message", func() {
+			flag := &JobResourceFlag{}
+
+			err := flag.UnmarshalFlag("some-pipeline/some-job")
+			Expect(err).To(MatchError("argument format should be <pipeline>/<job>:<resource>"))
+		})
+	})
+
+	Context("when there is only a pipeline specified", func() {
+		It("displays an error message", func() {
+			flag := &JobResourceFlag{}
+
+			err := flag.UnmarshalFlag("some-pipeline:some-resource")
+			Expect(err).To(MatchError("argument format should be <pipeline>/<job>"))
+		})
+	})
+})
@@ -0,0 +1,20 @@
+package flaghelpers
+
+import "strings"
+
+// JobsFlag is a JobFlag that, for commands whose pipeline can be given by
+// another flag, also accepts bare job names. Each time it is given one, the
+// name is added to Names.
+type JobsFlag struct {
+	JobFlag
+	Names []string
+}
+
+func (jobs *JobsFlag) UnmarshalFlag(value string) error {
+	if value != "" && !strings.Contains(value, "/") {
+		jobs.Names = append(jobs.Names, value)
+		return nil
+	}
+
+	return jobs.JobFlag.UnmarshalFlag(value)
+}
@@ -0,0 +1,40 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JobsFlag", func() {
+	It("accepts a job of a pipeline", func() {
+		jobsFlag := &JobsFlag{}
+
+		err := jobsFlag.UnmarshalFlag("some-pipeline/some-job")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(jobsFlag.PipelineName).To(Equal("some-pipeline"))
+		Expect(jobsFlag.JobName).To(Equal("some-job"))
+		Expect(jobsFlag.Names).To(BeEmpty())
+	})
+
+	It("collects bare job names", func() {
+		jobsFlag := &JobsFlag{}
+
+		Expect(jobsFlag.UnmarshalFlag("job-a")).To(Succeed())
+		Expect(jobsFlag.UnmarshalFlag("job-b")).To(Succeed())
+
+		Expect(jobsFlag.Names).To(Equal([]string{"job-a", "job-b"}))
+		Expect(jobsFlag.JobName).To(BeEmpty())
+	})
+
+	Context("when the job of a pipeline is missing", func() {
+		It("errors", func() {
+			jobsFlag := &JobsFlag{}
+
+			err := jobsFlag.UnmarshalFlag("some-pipeline/")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
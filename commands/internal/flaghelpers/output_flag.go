@@ -0,0 +1,27 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFlag is either an output to fetch, as NAME=PATH, or the format to
+// print a build's events in, which has no = to be mistaken for one.
+type OutputFlag struct {
+	Format string
+	Pair   OutputPairFlag
+}
+
+func (output *OutputFlag) UnmarshalFlag(value string) error {
+	if strings.Contains(value, "=") {
+		return output.Pair.UnmarshalFlag(value)
+	}
+
+	switch value {
+	case "text", "json":
+		output.Format = value
+		return nil
+	default:
+		return fmt.Errorf("invalid output '%s' (must be name=path, text or json)", value)
+	}
+}
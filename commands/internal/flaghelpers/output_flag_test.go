@@ -0,0 +1,37 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutputFlag", func() {
+	var outputFlag *OutputFlag
+
+	BeforeEach(func() {
+		outputFlag = &OutputFlag{}
+	})
+
+	It("parses an output pair", func() {
+		err := outputFlag.UnmarshalFlag("some-output=some/path")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*outputFlag).To(Equal(OutputFlag{Pair: OutputPairFlag{Name: "some-output", Path: "some/path"}}))
+	})
+
+	It("parses a format", func() {
+		err := outputFlag.UnmarshalFlag("json")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*outputFlag).To(Equal(OutputFlag{Format: "json"}))
+	})
+
+	Context("when it is neither", func() {
+		It("displays an error message", func() {
+			err := outputFlag.UnmarshalFlag("yaml")
+			Expect(err).To(MatchError("invalid output 'yaml' (must be name=path, text or json)"))
+		})
+	})
+})
@@ -0,0 +1,21 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type RegexpFlag struct {
+	Regexp *regexp.Regexp
+}
+
+func (flag *RegexpFlag) UnmarshalFlag(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression '%s': %s", value, err)
+	}
+
+	flag.Regexp = re
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RegexpFlag", func() {
+	var regexpFlag *RegexpFlag
+
+	BeforeEach(func() {
+		regexpFlag = new(RegexpFlag)
+	})
+
+	It("compiles the pattern", func() {
+		err := regexpFlag.UnmarshalFlag("err(or)?")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(regexpFlag.Regexp.MatchString("some error")).To(BeTrue())
+	})
+
+	Context("when the pattern is invalid", func() {
+		It("displays an error message", func() {
+			err := regexpFlag.UnmarshalFlag("err(")
+			Expect(err).To(MatchError(HavePrefix("invalid regular expression 'err(': ")))
+		})
+	})
+})
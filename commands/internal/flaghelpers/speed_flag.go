@@ -0,0 +1,26 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SpeedFlag is a playback speed multiplier, such as 4x or 0.5x. The x is
+// optional, and 0 means as fast as possible.
+type SpeedFlag float64
+
+func (speed *SpeedFlag) UnmarshalFlag(value string) error {
+	multiplier, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	if err != nil || multiplier < 0 {
+		return fmt.Errorf("invalid speed '%s' (must be a multiplier such as 4x or 0.5x, or 0)", value)
+	}
+
+	*speed = SpeedFlag(multiplier)
+
+	return nil
+}
+
+func (speed SpeedFlag) String() string {
+	return strconv.FormatFloat(float64(speed), 'f', -1, 64) + "x"
+}
@@ -0,0 +1,49 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpeedFlag", func() {
+	var speedFlag *SpeedFlag
+
+	BeforeEach(func() {
+		speedFlag = new(SpeedFlag)
+	})
+
+	It("parses a multiplier", func() {
+		err := speedFlag.UnmarshalFlag("4x")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*speedFlag).To(Equal(SpeedFlag(4)))
+		Expect(speedFlag.String()).To(Equal("4x"))
+	})
+
+	It("parses a fractional multiplier without an x", func() {
+		err := speedFlag.UnmarshalFlag("0.5")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*speedFlag).To(Equal(SpeedFlag(0.5)))
+		Expect(speedFlag.String()).To(Equal("0.5x"))
+	})
+
+	It("parses zero", func() {
+		err := speedFlag.UnmarshalFlag("0")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*speedFlag).To(BeZero())
+	})
+
+	Context("when the speed is not a number, or is negative", func() {
+		It("displays an error message", func() {
+			err := speedFlag.UnmarshalFlag("-2x")
+			Expect(err).To(MatchError("invalid speed '-2x' (must be a multiplier such as 4x or 0.5x, or 0)"))
+
+			err = speedFlag.UnmarshalFlag("fast")
+			Expect(err).To(MatchError("invalid speed 'fast' (must be a multiplier such as 4x or 0.5x, or 0)"))
+		})
+	})
+})
@@ -0,0 +1,290 @@
+package validatetaskhelpers
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"gopkg.in/yaml.v2"
+)
+
+// Problem is an error or warning found in a task config. Line is zero when
+// yaml.v2 does not say where it is.
+type Problem struct {
+	Line    int
+	Message string
+	Warning bool
+}
+
+func (problem Problem) Format(path string) string {
+	severity := "error"
+	if problem.Warning {
+		severity = "warning"
+	}
+
+	if problem.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", path, severity, problem.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s: %s", path, problem.Line, severity, problem.Message)
+}
+
+// yaml.v2 starts the messages of errors it can place with their line
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// ValidateTaskConfig returns every problem with the task config: the errors
+// that atc.LoadTaskConfig finds, and warnings for keys it would ignore. When
+// inputs or outputs are given, they are checked against those the task
+// declares.
+func ValidateTaskConfig(
+	configFile []byte,
+	inputs []flaghelpers.InputPairFlag,
+	outputs []flaghelpers.OutputPairFlag,
+) []Problem {
+	var doc interface{}
+	err := yaml.Unmarshal(configFile, &doc)
+	if err != nil {
+		return []Problem{yamlProblem(err.Error())}
+	}
+
+	if doc == nil {
+		return []Problem{{Message: "task config is empty"}}
+	}
+
+	// decoded again to keep the order of the keys, when there are keys
+	var root yaml.MapSlice
+	if _, ok := doc.(map[interface{}]interface{}); ok {
+		err = yaml.Unmarshal(configFile, &root)
+		if err != nil {
+			return []Problem{yamlProblem(err.Error())}
+		}
+	}
+
+	problems := unknownKeys(root, "", reflect.TypeOf(atc.TaskConfig{}))
+
+	_, err = atc.LoadTaskConfig(configFile)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, message := range typeErr.Errors {
+			problems = append(problems, yamlProblem(message))
+		}
+	} else if err != nil {
+		problems = append(problems, Problem{Message: err.Error()})
+	}
+
+	problems = append(problems, checkArtifacts(root, inputs, outputs)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+
+		// problems without a line go last
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+
+		return a.Line < b.Line
+	})
+
+	return problems
+}
+
+func yamlProblem(message string) Problem {
+	match := yamlLine.FindStringSubmatch(message)
+	if match == nil {
+		return Problem{Message: strings.TrimPrefix(message, "yaml: ")}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+
+	return Problem{Line: line, Message: match[2]}
+}
+
+var unmarshalerType = reflect.TypeOf((*interface {
+	UnmarshalYAML(func(interface{}) error) error
+})(nil)).Elem()
+
+// unknownKeys warns about the keys in value that t has no field for. path
+// is where value is in the config, such as inputs[0], to say where the keys
+// are.
+func unknownKeys(value interface{}, path string, t reflect.Type) []Problem {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// types that unmarshal themselves may take any shape
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	problems := []Problem{}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		mapping, ok := value.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+
+		fields := yamlFields(t)
+
+		for _, item := range mapping {
+			key := fmt.Sprint(item.Key)
+
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+
+			fieldType, found := fields[key]
+			if !found {
+				problems = append(problems, Problem{
+					Message: fmt.Sprintf("unknown key '%s'", keyPath),
+					Warning: true,
+				})
+
+				continue
+			}
+
+			problems = append(problems, unknownKeys(item.Value, keyPath, fieldType)...)
+		}
+
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		for i, item := range items {
+			problems = append(problems, unknownKeys(item, fmt.Sprintf("%s[%d]", path, i), t.Elem())...)
+		}
+	}
+
+	return problems
+}
+
+// yamlFields maps the keys a struct is unmarshaled from to the types they
+// are unmarshaled into
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+
+		if len(tag) > 1 && tag[1] == "inline" {
+			for name, fieldType := range yamlFields(field.Type) {
+				fields[name] = fieldType
+			}
+
+			continue
+		}
+
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+func checkArtifacts(
+	root yaml.MapSlice,
+	inputs []flaghelpers.InputPairFlag,
+	outputs []flaghelpers.OutputPairFlag,
+) []Problem {
+	problems := []Problem{}
+
+	taskInputs := artifactNames(root, "inputs")
+	taskOutputs := artifactNames(root, "outputs")
+
+	providedInputs := map[string]bool{}
+	for _, input := range inputs {
+		providedInputs[input.Name] = true
+
+		if !taskInputs[input.Name] {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("unknown input `%s`", input.Name),
+			})
+		}
+	}
+
+	if len(inputs) > 0 {
+		for _, name := range sortedNames(taskInputs) {
+			if !providedInputs[name] {
+				problems = append(problems, Problem{
+					Message: fmt.Sprintf("input `%s` is not provided with -i", name),
+					Warning: true,
+				})
+			}
+		}
+	}
+
+	for _, output := range outputs {
+		if !taskOutputs[output.Name] {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("unknown output '%s'", output.Name),
+			})
+		}
+	}
+
+	return problems
+}
+
+// artifactNames returns the names of the task's inputs or outputs
+func artifactNames(root yaml.MapSlice, key string) map[string]bool {
+	names := map[string]bool{}
+
+	artifacts, ok := valueOf(root, key).([]interface{})
+	if !ok {
+		return names
+	}
+
+	for _, artifact := range artifacts {
+		mapping, ok := artifact.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		if name, ok := valueOf(mapping, "name").(string); ok {
+			names[name] = true
+		}
+	}
+
+	return names
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func valueOf(mapping yaml.MapSlice, key string) interface{} {
+	for _, item := range mapping {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+
+	return nil
+}
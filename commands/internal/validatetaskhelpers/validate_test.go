@@ -0,0 +1,198 @@
+package validatetaskhelpers_test
+
+import (
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	. "github.com/concourse/fly/commands/internal/validatetaskhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateTaskConfig", func() {
+	var (
+		configFile string
+		inputs     []flaghelpers.InputPairFlag
+		outputs    []flaghelpers.OutputPairFlag
+
+		problems []Problem
+	)
+
+	BeforeEach(func() {
+		inputs = nil
+		outputs = nil
+	})
+
+	JustBeforeEach(func() {
+		problems = ValidateTaskConfig([]byte(configFile), inputs, outputs)
+	})
+
+	Context("when the config is valid", func() {
+		BeforeEach(func() {
+			configFile = `---
+platform: linux
+
+inputs:
+- name: some-input
+
+outputs:
+- name: some-output
+
+run:
+  path: ls
+`
+		})
+
+		It("finds no problems", func() {
+			Expect(problems).To(BeEmpty())
+		})
+
+		Context("when the given inputs and outputs match", func() {
+			BeforeEach(func() {
+				inputs = []flaghelpers.InputPairFlag{{Name: "some-input", Path: "."}}
+				outputs = []flaghelpers.OutputPairFlag{{Name: "some-output", Path: "out"}}
+			})
+
+			It("finds no problems", func() {
+				Expect(problems).To(BeEmpty())
+			})
+		})
+
+		Context("when an unknown input or output is given", func() {
+			BeforeEach(func() {
+				inputs = []flaghelpers.InputPairFlag{{Name: "some-input", Path: "."}, {Name: "bogus-input", Path: "."}}
+				outputs = []flaghelpers.OutputPairFlag{{Name: "bogus-output", Path: "out"}}
+			})
+
+			It("reports them as errors", func() {
+				Expect(problems).To(Equal([]Problem{
+					{Message: "unknown input `bogus-input`"},
+					{Message: "unknown output 'bogus-output'"},
+				}))
+			})
+		})
+
+		Context("when inputs are given but not all of the task's", func() {
+			BeforeEach(func() {
+				configFile = `---
+platform: linux
+
+inputs:
+- name: some-input
+- name: other-input
+
+run:
+  path: ls
+`
+
+				inputs = []flaghelpers.InputPairFlag{{Name: "some-input", Path: "."}}
+			})
+
+			It("warns about the missing input", func() {
+				Expect(problems).To(Equal([]Problem{
+					{Message: "input `other-input` is not provided with -i", Warning: true},
+				}))
+			})
+		})
+	})
+
+	Context("when the config has unknown keys", func() {
+		BeforeEach(func() {
+			configFile = `---
+platform: linux
+
+inputs:
+- name: some-input
+  paht: somewhere
+
+run:
+  path: ls
+  argz: [-al]
+
+parmas:
+  FOO: bar
+`
+		})
+
+		It("warns about each of them, saying where they are", func() {
+			Expect(problems).To(Equal([]Problem{
+				{Message: "unknown key 'inputs[0].paht'", Warning: true},
+				{Message: "unknown key 'run.argz'", Warning: true},
+				{Message: "unknown key 'parmas'", Warning: true},
+			}))
+		})
+	})
+
+	Context("when the config is invalid", func() {
+		BeforeEach(func() {
+			configFile = `---
+inputs:
+- name: some-input
+
+run:
+  path: ls
+`
+		})
+
+		It("reports the error", func() {
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Message).To(ContainSubstring("missing 'platform'"))
+			Expect(problems[0].Warning).To(BeFalse())
+		})
+	})
+
+	Context("when a value has the wrong type", func() {
+		BeforeEach(func() {
+			configFile = `---
+platform: linux
+
+inputs: some-input
+
+run:
+  path: ls
+`
+		})
+
+		It("reports the error on its line", func() {
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Line).To(Equal(4))
+			Expect(problems[0].Warning).To(BeFalse())
+		})
+	})
+
+	Context("when the config is not valid YAML", func() {
+		BeforeEach(func() {
+			configFile = `---
+platform: linux
+run: [
+`
+		})
+
+		It("reports the parse error", func() {
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Line).NotTo(BeZero())
+			Expect(problems[0].Warning).To(BeFalse())
+		})
+	})
+
+	Context("when the config is empty", func() {
+		BeforeEach(func() {
+			configFile = ""
+		})
+
+		It("reports an error", func() {
+			Expect(problems).To(Equal([]Problem{{Message: "task config is empty"}}))
+		})
+	})
+})
+
+var _ = Describe("Problem", func() {
+	It("formats with its position", func() {
+		problem := Problem{Line: 3, Message: "cannot unmarshal !!str `foo`"}
+		Expect(problem.Format("task.yml")).To(Equal("task.yml:3: error: cannot unmarshal !!str `foo`"))
+	})
+
+	It("formats without a position", func() {
+		problem := Problem{Message: "unknown input `foo`"}
+		Expect(problem.Format("task.yml")).To(Equal("task.yml: error: unknown input `foo`"))
+	})
+})
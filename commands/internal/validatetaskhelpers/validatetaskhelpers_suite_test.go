@@ -0,0 +1,13 @@
+package validatetaskhelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestValidatetaskhelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Validate-Task Helpers Suite")
+}
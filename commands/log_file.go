@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/concourse/fly/ui"
+)
+
+// openLogFile creates the file that --log-file copies a build's rendered
+// output to. Escape sequences are stripped from the copy, so that it reads
+// cleanly outside of a terminal, unless keepANSI is set.
+func openLogFile(path string, keepANSI bool) (io.WriteCloser, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %s", err)
+	}
+
+	if keepANSI {
+		return file, nil
+	}
+
+	return ui.NewANSIStripper(file), nil
+}
+
+// closeLogFile writes out the rest of the log file, if there is one, and
+// closes it. Commands that exit with os.Exit must call it first, as that
+// skips deferred calls.
+func closeLogFile(logFile io.WriteCloser) {
+	if logFile == nil {
+		return
+	}
+
+	err := logFile.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to close log file:", err)
+	}
+}
+
+// teeLog writes to dst, and to the log file too if there is one
+func teeLog(dst io.Writer, logFile io.Writer) io.Writer {
+	if logFile == nil {
+		return dst
+	}
+
+	return io.MultiWriter(dst, logFile)
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+)
+
+type ReplayCommand struct {
+	Speed      flaghelpers.SpeedFlag `          long:"speed" value-name:"MULTIPLIER" default:"1x" description:"How fast to play back the build, e.g. 4x. 0 plays it back at once"`
+	Output     string                `          long:"output" value-name:"FORMAT" default:"text" choice:"text" choice:"json" description:"How to print the build's events: as text, or as one JSON object per event"`
+	Timestamps string                `          long:"timestamps" value-name:"FORMAT" optional:"true" optional-value:"absolute" choice:"absolute" choice:"relative" description:"Prefix each line of the build's log with the time it was printed, or with the time since the build started"`
+	Summary    bool                  `          long:"summary"                               description:"Once the build finishes, print a table of its steps with how long each took"`
+
+	Args struct {
+		Recording flaghelpers.PathFlag `positional-arg-name:"RECORDING" required:"true" description:"A file of events saved with 'watch --record'"`
+	} `positional-args:"yes"`
+}
+
+func (command *ReplayCommand) Execute(args []string) error {
+	recording, err := os.Open(string(command.Args.Recording))
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %s", err)
+	}
+
+	events := eventstream.NewReplayEventStream(recording, float64(command.Speed))
+
+	renderer := eventstream.NewRenderer(command.Output, os.Stdout, eventstream.RenderOptions{
+		Timestamps: command.Timestamps,
+		Summary:    command.Summary,
+	})
+
+	exitCode := eventstream.RenderWith(renderer, events)
+
+	events.Close()
+
+	os.Exit(exitCode)
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/executehelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type RerunBuildCommand struct {
+	Job      flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB"                 description:"The job whose build to rerun"`
+	Build    string              `short:"b" long:"build" required:"true"                           description:"The build to rerun: its name, with --job, or else its ID"`
+	Watch    bool                `short:"w" long:"watch"                                           description:"Stream the new build's output until it finishes, and exit with its status"`
+	WithPuts bool                `          long:"with-puts"                                       description:"Also run the job's puts, creating new versions of the pipeline's resources"`
+}
+
+// Execute runs a new one-off build with the same versions of the same inputs
+// as the given one. A job's builds are rerun from the job's current config,
+// as their plans cannot be read back in full, so any changes made to the job
+// since are picked up, with a warning; its puts are left out unless asked
+// for. One-off builds are rerun from the plan that was saved when they were
+// executed.
+func (command *RerunBuildCommand) Execute(args []string) error {
+	client, err := rc.TargetClient(Fly.Target)
+	if err != nil {
+		return err
+	}
+	err = rc.ValidateClient(client, Fly.Target)
+	if err != nil {
+		return err
+	}
+
+	build, err := GetBuild(client, command.Job.JobName, command.Build, command.Job.PipelineName)
+	if err != nil {
+		return err
+	}
+
+	var plan atc.Plan
+	if build.JobName != "" {
+		plan, err = command.jobRerunPlan(client, build)
+	} else {
+		plan, err = oneOffRerunPlan(build)
+	}
+	if err != nil {
+		return err
+	}
+
+	rerun, err := client.CreateBuild(plan)
+	if err != nil {
+		return err
+	}
+
+	// a job's plan has the sources of its resources in it, credentials and
+	// all, so only one-off plans are kept
+	if build.JobName != "" {
+		fmt.Printf("started build %d, rerunning '%s/%s' #%s\n", rerun.ID, build.PipelineName, build.JobName, build.Name)
+	} else {
+		saveBuildPlan(rerun, plan)
+		fmt.Printf("started build %d, rerunning build %d\n", rerun.ID, build.ID)
+	}
+
+	if !command.Watch {
+		return nil
+	}
+
+	eventSource, err := client.BuildEvents(strconv.Itoa(rerun.ID))
+	if err != nil {
+		return err
+	}
+
+	exitCode := eventstream.Render(os.Stdout, eventSource)
+
+	eventSource.Close()
+
+	os.Exit(exitCode)
+
+	return nil
+}
+
+func (command *RerunBuildCommand) jobRerunPlan(client concourse.Client, build atc.Build) (atc.Plan, error) {
+	versions, err := GetBuildVersions(client, build)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	config, _, _, found, err := client.PipelineConfig(build.PipelineName)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	if !found {
+		return atc.Plan{}, fmt.Errorf("pipeline '%s' not found", build.PipelineName)
+	}
+
+	plan, leftOut, err := executehelpers.JobRerunPlan(config, build.JobName, versions, command.WithPuts)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: rerunning with the current config of '%s/%s', not the one build #%s ran with; any changes to its steps since then will be picked up\n", build.PipelineName, build.JobName, build.Name)
+
+	if len(leftOut) > 0 {
+		fmt.Fprintf(os.Stderr, "leaving out put steps: %s; run with --with-puts to include them\n", strings.Join(leftOut, ", "))
+	}
+
+	return plan, nil
+}
+
+func oneOffRerunPlan(build atc.Build) (atc.Plan, error) {
+	plan, err := rc.LoadBuildPlan(Fly.Target, build.ID)
+	if err != nil {
+		return atc.Plan{}, err
+	}
+
+	if executehelpers.PlanUsesPipes(plan) {
+		return atc.Plan{}, fmt.Errorf("build %d had inputs uploaded or outputs downloaded by fly, which cannot be done again; run 'execute' instead", build.ID)
+	}
+
+	return plan, nil
+}
@@ -1,20 +1,30 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
 )
 
 type TriggerJobCommand struct {
-	Job flaghelpers.JobFlag `short:"j" long:"job" required:"true" value-name:"PIPELINE/JOB"   description:"Name of a job to start"`
+	Job   flaghelpers.JobFlag `short:"j" long:"job" required:"true" value-name:"PIPELINE/JOB"   description:"Name of a job to start"`
+	Watch bool                `short:"w" long:"watch"                                          description:"Stream the build's output until it finishes, and exit with its status"`
+	Wait  bool                `          long:"wait"                                           description:"Wait for the build to finish, printing only its final status, and exit with its status"`
 }
 
 func (command *TriggerJobCommand) Execute(args []string) error {
 	pipelineName, jobName := command.Job.PipelineName, command.Job.JobName
 
+	if command.Watch && command.Wait {
+		return errors.New("--watch and --wait cannot be used together")
+	}
+
 	client, err := rc.TargetClient(Fly.Target)
 	if err != nil {
 		return err
@@ -24,10 +34,34 @@ func (command *TriggerJobCommand) Execute(args []string) error {
 		return err
 	}
 
-	_, err = client.CreateJobBuild(pipelineName, jobName)
+	build, err := client.CreateJobBuild(pipelineName, jobName)
 	if err != nil {
 		displayhelpers.FailWithErrorf("pipeline/job '%s/%s' not found\n", err, pipelineName, jobName)
 	}
-	fmt.Printf("started '%s/%s'\n", pipelineName, jobName)
+
+	if !command.Watch && !command.Wait {
+		fmt.Printf("started '%s/%s'\n", pipelineName, jobName)
+		return nil
+	}
+
+	var renderer eventstream.Renderer
+	if command.Watch {
+		fmt.Printf("started '%s/%s' #%s\n", pipelineName, jobName, build.Name)
+		renderer = eventstream.NewTextRenderer(os.Stdout, eventstream.RenderOptions{})
+	} else {
+		renderer = eventstream.NewStatusRenderer(os.Stdout)
+	}
+
+	eventSource, err := client.BuildEvents(strconv.Itoa(build.ID))
+	if err != nil {
+		return err
+	}
+
+	exitCode := eventstream.RenderWith(renderer, eventSource)
+
+	eventSource.Close()
+
+	os.Exit(exitCode)
+
 	return nil
 }
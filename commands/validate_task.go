@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/validatetaskhelpers"
+)
+
+type ValidateTaskCommand struct {
+	TaskConfig flaghelpers.PathFlag         `short:"c" long:"config" required:"true"  description:"The task config to validate"`
+	Inputs     []flaghelpers.InputPairFlag  `short:"i" long:"input"  value-name:"NAME=PATH" description:"An input the task will be given, to check against those it declares (can be specified multiple times)"`
+	Outputs    []flaghelpers.OutputPairFlag `short:"o" long:"output" value-name:"NAME=PATH" description:"An output that will be fetched from the task, to check against those it declares (can be specified multiple times)"`
+	Strict     bool                         `short:"s" long:"strict"                   description:"Fail on warnings as well as errors"`
+}
+
+func (command *ValidateTaskCommand) Execute(args []string) error {
+	configPath := string(command.TaskConfig)
+
+	configFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read task config: %s", err)
+	}
+
+	problems := validatetaskhelpers.ValidateTaskConfig(configFile, command.Inputs, command.Outputs)
+
+	failed := false
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem.Format(configPath))
+
+		if !problem.Warning || command.Strict {
+			failed = true
+		}
+	}
+
+	if failed {
+		displayhelpers.Failf("task config is invalid")
+	}
+
+	fmt.Println("looks good")
+
+	return nil
+}
@@ -2,16 +2,39 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/concourse/atc"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+	goeventstream "github.com/concourse/go-concourse/concourse/eventstream"
 )
 
+// how often to look for builds that have started since watching began
+const watchPollInterval = 2 * time.Second
+
 type WatchCommand struct {
-	Job   flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB"   description:"Watches builds of the given job"`
-	Build string              `short:"b" long:"build"                               description:"Watches a specific build"`
+	Job              flaghelpers.JobsFlag   `short:"j" long:"job"   value-name:"PIPELINE/JOB"   description:"Watches builds of the given job. With --pipeline, a job's name alone, to only watch builds of that job (can be specified multiple times)"`
+	Build            string                 `short:"b" long:"build"                               description:"Watches a specific build"`
+	Output           string                 `          long:"output" value-name:"FORMAT" default:"text" choice:"text" choice:"json" description:"How to print the build's events: as text, or as one JSON object per event"`
+	Timestamps       string                 `          long:"timestamps" value-name:"FORMAT" optional:"true" optional-value:"absolute" choice:"absolute" choice:"relative" description:"Prefix each line of the build's log with the time it was printed, or with the time since the build started"`
+	Summary          bool                   `          long:"summary"                               description:"Once the build finishes, print a table of its steps with how long each took"`
+	ReconnectTimeout time.Duration          `          long:"reconnect-timeout" value-name:"DURATION" default:"5m" description:"How long to keep trying to reconnect when the connection to the build's events is lost. 0 gives up straight away"`
+	Record           string                 `          long:"record" value-name:"PATH" description:"Save the build's events to a file as they are received, to play back with 'replay'"`
+	Pipeline         string                 `short:"p" long:"pipeline" value-name:"PIPELINE"  description:"Watches every running build of the given pipeline, including those that start while watching"`
+	Follow           bool                   `          long:"follow"                                description:"With --job, keep watching the job, attaching to each build as it starts"`
+	Count            int                    `          long:"count" value-name:"N"               description:"With --follow, stop after watching this many builds"`
+	Grep             flaghelpers.RegexpFlag `          long:"grep" value-name:"REGEX"           description:"Only print the lines of the build's log that match"`
+	Context          int                    `short:"C" long:"context" value-name:"N"             description:"With --grep, also print N lines either side of each match"`
+	Highlight        flaghelpers.RegexpFlag `          long:"highlight" value-name:"REGEX"      description:"Color the parts of the build's log that match, printing all of it"`
+	LogFile          string                 `          long:"log-file" value-name:"PATH"        description:"Also write the build's output to a file, without colors"`
+	LogFileANSI      bool                   `          long:"log-file-keep-ansi"                description:"Keep colors and other escape sequences in the --log-file copy"`
+
+	logFile io.WriteCloser
 }
 
 func (command *WatchCommand) Execute(args []string) error {
@@ -24,21 +47,104 @@ func (command *WatchCommand) Execute(args []string) error {
 		return err
 	}
 
+	if command.LogFile != "" {
+		command.logFile, err = openLogFile(command.LogFile, command.LogFileANSI)
+		if err != nil {
+			return err
+		}
+
+		defer closeLogFile(command.logFile)
+	}
+
+	if command.Pipeline != "" {
+		return command.watchPipeline(client)
+	}
+
+	if len(command.Job.Names) > 0 {
+		return fmt.Errorf("job '%s' must be given as PIPELINE/JOB, or with --pipeline", command.Job.Names[0])
+	}
+
 	build, err := GetBuild(client, command.Job.JobName, command.Build, command.Job.PipelineName)
 	if err != nil {
 		return err
 	}
 
-	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", build.ID))
+	if command.Follow {
+		return command.follow(client, build)
+	}
+
+	exitCode, err := command.streamBuild(client, build)
 	if err != nil {
 		return err
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	command.exit(exitCode)
 
-	eventSource.Close()
+	return nil
+}
 
+// exit closes the log file first, as os.Exit skips deferred calls
+func (command *WatchCommand) exit(exitCode int) {
+	closeLogFile(command.logFile)
 	os.Exit(exitCode)
+}
 
-	return nil
+func (command *WatchCommand) streamBuild(client concourse.Client, build atc.Build) (int, error) {
+	connect := eventstream.BuildEventsConnector(client, fmt.Sprintf("%d", build.ID))
+
+	eventSource, err := eventstream.ConnectResuming(connect, command.ReconnectTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	eventSource.Notify = os.Stderr
+
+	var events goeventstream.EventStream = eventSource
+
+	var recording *os.File
+	if command.Record != "" {
+		recording, err = os.Create(command.Record)
+		if err != nil {
+			eventSource.Close()
+			return 0, fmt.Errorf("failed to create recording: %s", err)
+		}
+
+		events = eventstream.NewRecordingEventStream(eventSource, recording)
+	}
+
+	renderer := eventstream.NewRenderer(command.Output, command.stdout(), command.renderOptions(build))
+
+	exitCode := eventstream.RenderWith(renderer, events)
+
+	events.Close()
+
+	if recording != nil {
+		recording.Close()
+	}
+
+	return exitCode, nil
+}
+
+// stdout is where the builds' output goes: to the terminal, and to the log
+// file if there is one
+func (command *WatchCommand) stdout() io.Writer {
+	return teeLog(os.Stdout, command.logFile)
+}
+
+// the build may have started long before it is watched, so relative
+// timestamps count from its start time
+func (command *WatchCommand) renderOptions(build atc.Build) eventstream.RenderOptions {
+	options := eventstream.RenderOptions{
+		Timestamps: command.Timestamps,
+		Summary:    command.Summary,
+		Grep:       command.Grep.Regexp,
+		Context:    command.Context,
+		Highlight:  command.Highlight.Regexp,
+	}
+
+	if build.StartTime != 0 {
+		options.StartTime = time.Unix(build.StartTime, 0)
+	}
+
+	return options
 }
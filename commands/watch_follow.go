@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+// follow watches the build, then each build of the job after it as it
+// starts, until --count builds have been watched. It exits with the exit
+// code of the last one.
+func (command *WatchCommand) follow(client concourse.Client, build atc.Build) error {
+	switch {
+	case command.Job.JobName == "" || command.Build != "":
+		return errors.New("--follow can only be used with --job, and not with --build")
+	case command.Record != "":
+		return errors.New("--follow cannot be used with --record")
+	}
+
+	for watched := 1; ; watched++ {
+		if command.Output != eventstream.JSONFormat {
+			if watched > 1 {
+				fmt.Fprintln(command.stdout(), "")
+			}
+
+			printBuildSeparator(command.stdout(), build)
+		}
+
+		exitCode, err := command.streamBuild(client, build)
+		if err != nil {
+			return err
+		}
+
+		if command.Count > 0 && watched >= command.Count {
+			command.exit(exitCode)
+		}
+
+		build, err = command.nextBuild(client, build)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// nextBuild waits for a build of the job newer than the given one
+func (command *WatchCommand) nextBuild(client concourse.Client, last atc.Build) (atc.Build, error) {
+	for {
+		job, found, err := client.Job(command.Job.PipelineName, command.Job.JobName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to get job:", err)
+		} else if !found {
+			return atc.Build{}, errors.New("job not found")
+		} else if job.NextBuild != nil && job.NextBuild.ID > last.ID {
+			return *job.NextBuild, nil
+		} else if job.FinishedBuild != nil && job.FinishedBuild.ID > last.ID {
+			// it started and finished since the last look
+			return *job.FinishedBuild, nil
+		}
+
+		time.Sleep(watchPollInterval)
+	}
+}
+
+func printBuildSeparator(dst io.Writer, build atc.Build) {
+	fmt.Fprintln(dst, color.New(color.Bold).Sprintf("==== %s #%s ====", build.JobName, build.Name))
+}
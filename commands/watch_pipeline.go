@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type finishedBuild struct {
+	build    atc.Build
+	exitCode int
+}
+
+// watchPipeline streams every running build of the pipeline side by side,
+// picking up builds as they start, until none are left running. It exits
+// with the highest of the builds' exit codes.
+func (command *WatchCommand) watchPipeline(client concourse.Client) error {
+	switch {
+	case command.Job.JobName != "" && command.Job.PipelineName != command.Pipeline:
+		return fmt.Errorf("--job '%s/%s' is not in pipeline '%s'", command.Job.PipelineName, command.Job.JobName, command.Pipeline)
+	case command.Build != "":
+		return errors.New("--pipeline cannot be used with --build")
+	case command.Output == eventstream.JSONFormat:
+		return errors.New("--pipeline cannot be used with --output json, as the builds' events are interleaved")
+	case command.Record != "":
+		return errors.New("--pipeline cannot be used with --record")
+	}
+
+	running, err := command.runningBuilds(client)
+	if err != nil {
+		return err
+	}
+
+	if len(running) == 0 {
+		return fmt.Errorf("no builds are running in pipeline '%s'", command.Pipeline)
+	}
+
+	watching := map[int]bool{}
+	finished := make(chan finishedBuild)
+	active := 0
+
+	watch := func(builds []atc.Build) {
+		for _, build := range builds {
+			if watching[build.ID] {
+				continue
+			}
+
+			watching[build.ID] = true
+			active++
+
+			go func(build atc.Build) {
+				finished <- finishedBuild{
+					build:    build,
+					exitCode: command.streamPipelineBuild(client, build),
+				}
+			}(build)
+		}
+	}
+
+	watch(running)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	exitCode := 0
+	for {
+		select {
+		case f := <-finished:
+			active--
+
+			if f.exitCode > exitCode {
+				exitCode = f.exitCode
+			}
+
+		case <-ticker.C:
+			running, err := command.runningBuilds(client)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "failed to look for new builds:", err)
+				continue
+			}
+
+			watch(running)
+
+			// the builds that have finished may have triggered others, so
+			// only stop after looking for them once more
+			if active == 0 {
+				command.exit(exitCode)
+			}
+		}
+	}
+}
+
+func (command *WatchCommand) runningBuilds(client concourse.Client) ([]atc.Build, error) {
+	jobs, err := client.ListJobs(command.Pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %s", err)
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range command.Job.Names {
+		wanted[name] = false
+	}
+
+	if command.Job.JobName != "" {
+		wanted[command.Job.JobName] = false
+	}
+
+	builds := []atc.Build{}
+	for _, job := range jobs {
+		if len(wanted) > 0 {
+			if _, found := wanted[job.Name]; !found {
+				continue
+			}
+
+			wanted[job.Name] = true
+		}
+
+		if job.NextBuild != nil && job.NextBuild.Status == string(atc.StatusStarted) {
+			builds = append(builds, *job.NextBuild)
+		}
+	}
+
+	for name, found := range wanted {
+		if !found {
+			return nil, fmt.Errorf("job '%s' not found in pipeline '%s'", name, command.Pipeline)
+		}
+	}
+
+	return builds, nil
+}
+
+func (command *WatchCommand) streamPipelineBuild(client concourse.Client, build atc.Build) int {
+	name := fmt.Sprintf("%s#%s", build.JobName, build.Name)
+
+	log := ui.NewPrefixWriter(command.stdout(), ui.NameColor(build.JobName).Sprint(name)+" ")
+	defer log.Flush()
+
+	connect := eventstream.BuildEventsConnector(client, strconv.Itoa(build.ID))
+
+	eventSource, err := eventstream.ConnectResuming(connect, command.ReconnectTimeout)
+	if err != nil {
+		fmt.Fprintln(log, "failed to stream build:", err)
+		return 255
+	}
+
+	eventSource.Notify = log
+
+	exitCode := eventstream.RenderWith(eventstream.NewTextRenderer(log, command.renderOptions(build)), eventSource)
+	eventSource.Close()
+
+	return exitCode
+}
@@ -1,26 +1,58 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"syscall"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/template"
+	"gopkg.in/yaml.v2"
 )
 
-func LoadTaskConfig(configPath string, args []string) (atc.TaskConfig, error) {
+// ReadTaskConfig reads the task config at configPath. When variables is
+// non-nil, {{var}} references in the file are interpolated before parsing.
+func ReadTaskConfig(configPath string, variables template.Variables) (atc.TaskConfig, error) {
 	configFile, err := ioutil.ReadFile(configPath)
 	if err != nil {
 		return atc.TaskConfig{}, fmt.Errorf("failed to read task config: %s", err)
 	}
 
-	config, err := atc.LoadTaskConfig(configFile)
-	if err != nil {
-		return atc.TaskConfig{}, err
+	if variables != nil {
+		configFile, err = template.Evaluate(configFile, variables)
+		if err != nil {
+			return atc.TaskConfig{}, fmt.Errorf("failed to evaluate variables into task config: %s", err)
+		}
 	}
 
+	return atc.LoadTaskConfig(configFile)
+}
+
+// OverrideTaskConfig appends args to the task's run args and overrides its
+// params, in increasing order of precedence, with the given step params,
+// same-named environment variables, and the contents of each params file.
+func OverrideTaskConfig(config atc.TaskConfig, args []string, params atc.Params, paramsFiles []string) (atc.TaskConfig, error) {
 	config.Run.Args = append(config.Run.Args, args...)
 
+	if len(params) > 0 && config.Params == nil {
+		config.Params = map[string]string{}
+	}
+
+	for k, v := range params {
+		if s, ok := v.(string); ok {
+			config.Params[k] = s
+			continue
+		}
+
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return atc.TaskConfig{}, err
+		}
+
+		config.Params[k] = string(payload)
+	}
+
 	for k, _ := range config.Params {
 		env, found := syscall.Getenv(k)
 		if found {
@@ -28,5 +60,35 @@ func LoadTaskConfig(configPath string, args []string) (atc.TaskConfig, error) {
 		}
 	}
 
+	for _, path := range paramsFiles {
+		params, err := loadParamsFromFile(path)
+		if err != nil {
+			return atc.TaskConfig{}, err
+		}
+
+		if config.Params == nil {
+			config.Params = map[string]string{}
+		}
+
+		for k, v := range params {
+			config.Params[k] = v
+		}
+	}
+
 	return config, nil
 }
+
+func loadParamsFromFile(path string) (map[string]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read params file: %s", err)
+	}
+
+	var params map[string]string
+	err = yaml.Unmarshal(contents, &params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse params file (%s): %s", path, err)
+	}
+
+	return params, nil
+}
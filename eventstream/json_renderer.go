@@ -0,0 +1,54 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+)
+
+// JSONRenderer writes each event as a JSON object on its own line, for
+// tools to consume.
+type JSONRenderer struct {
+	encoder *json.Encoder
+}
+
+type jsonEvent struct {
+	Type      atc.EventType    `json:"type"`
+	Version   atc.EventVersion `json:"version"`
+	Timestamp int64            `json:"timestamp"`
+	Origin    *json.RawMessage `json:"origin,omitempty"`
+	Payload   atc.Event        `json:"payload"`
+}
+
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+func NewJSONRenderer(dst io.Writer) *JSONRenderer {
+	return &JSONRenderer{encoder: json.NewEncoder(dst)}
+}
+
+// RenderEvent writes the event with its type and version. Its timestamp is
+// the event's own time, or for events without one, such as logs, the time
+// it was received.
+func (renderer *JSONRenderer) RenderEvent(ev atc.Event) {
+	header := headerOf(ev)
+
+	if header.Time == 0 {
+		header.Time = time.Now().Unix()
+	}
+
+	renderer.encoder.Encode(jsonEvent{
+		Type:      ev.EventType(),
+		Version:   ev.Version(),
+		Timestamp: header.Time,
+		Origin:    header.Origin,
+		Payload:   ev,
+	})
+}
+
+func (renderer *JSONRenderer) RenderError(err error) {
+	renderer.encoder.Encode(jsonError{Error: err.Error()})
+}
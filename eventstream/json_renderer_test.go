@@ -0,0 +1,158 @@
+package eventstream_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/go-concourse/concourse/eventstream/fakes"
+)
+
+var _ = Describe("JSON Renderer", func() {
+	var (
+		out    *gbytes.Buffer
+		stream *fakes.FakeEventStream
+
+		receivedEvents chan<- atc.Event
+
+		exitStatus int
+	)
+
+	type renderedEvent struct {
+		Type      string                 `json:"type"`
+		Version   string                 `json:"version"`
+		Timestamp int64                  `json:"timestamp"`
+		Origin    map[string]interface{} `json:"origin"`
+		Payload   map[string]interface{} `json:"payload"`
+	}
+
+	renderedEvents := func() []renderedEvent {
+		events := []renderedEvent{}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(out.Contents())), "\n") {
+			var ev renderedEvent
+			err := json.Unmarshal([]byte(line), &ev)
+			Expect(err).NotTo(HaveOccurred())
+
+			events = append(events, ev)
+		}
+
+		return events
+	}
+
+	BeforeEach(func() {
+		out = gbytes.NewBuffer()
+		stream = new(fakes.FakeEventStream)
+
+		events := make(chan atc.Event, 100)
+		receivedEvents = events
+
+		stream.NextEventStub = func() (atc.Event, error) {
+			select {
+			case ev := <-events:
+				return ev, nil
+			default:
+				return nil, io.EOF
+			}
+		}
+	})
+
+	JustBeforeEach(func() {
+		exitStatus = eventstream.RenderWith(eventstream.NewJSONRenderer(out), stream)
+	})
+
+	Context("when a Log event is received", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.Log{
+				Payload: "hello",
+				Origin: event.Origin{
+					Name:   "some-task",
+					Type:   event.OriginTypeTask,
+					Source: event.OriginSourceStdout,
+				},
+			}
+		})
+
+		It("writes it as one JSON object, with its type, version and origin", func() {
+			events := renderedEvents()
+			Expect(events).To(HaveLen(1))
+
+			ev := events[0]
+			Expect(ev.Type).To(Equal(string(event.Log{}.EventType())))
+			Expect(ev.Version).To(Equal(string(event.Log{}.Version())))
+			Expect(ev.Origin).To(HaveKeyWithValue("name", "some-task"))
+			Expect(ev.Payload).To(HaveKeyWithValue("payload", "hello"))
+		})
+
+		It("stamps it with the time it was received", func() {
+			Expect(renderedEvents()[0].Timestamp).NotTo(BeZero())
+		})
+	})
+
+	Context("when events with a time are received", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.FinishTask{
+				Time:       1234,
+				ExitStatus: 42,
+			}
+
+			receivedEvents <- event.Status{
+				Status: atc.StatusFailed,
+				Time:   5678,
+			}
+		})
+
+		It("stamps them with their time", func() {
+			events := renderedEvents()
+			Expect(events).To(HaveLen(2))
+
+			Expect(events[0].Timestamp).To(Equal(int64(1234)))
+			Expect(events[1].Timestamp).To(Equal(int64(5678)))
+			Expect(events[1].Payload).To(HaveKeyWithValue("status", "failed"))
+		})
+
+		It("exits with the status from the FinishTask event, as the text renderer does", func() {
+			Expect(exitStatus).To(Equal(42))
+		})
+	})
+
+	Context("when the build errors", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.Status{
+				Status: atc.StatusErrored,
+			}
+		})
+
+		It("exits 2", func() {
+			Expect(exitStatus).To(Equal(2))
+		})
+	})
+
+	Context("when the next event cannot be read", func() {
+		BeforeEach(func() {
+			stream.NextEventStub = nil
+			stream.NextEventReturns(nil, errors.New("oh no"))
+		})
+
+		It("writes the error as a JSON object and exits 255", func() {
+			var rendered map[string]string
+			err := json.Unmarshal(out.Contents(), &rendered)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(rendered).To(Equal(map[string]string{
+				"error": "failed to parse next event: oh no",
+			}))
+
+			Expect(exitStatus).To(Equal(255))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/go-concourse/concourse/eventstream"
+)
+
+// A recording is a file of JSON objects, one per line, each holding an
+// event in the form the ATC sends it along with when it was received.
+type recordedEvent struct {
+	// since the first event
+	Offset time.Duration `json:"offset"`
+
+	Event event.Message `json:"event"`
+}
+
+// RecordingEventStream writes each event read from src to dst, so that the
+// build can be replayed later with ReplayEventStream.
+type RecordingEventStream struct {
+	src     eventstream.EventStream
+	encoder *json.Encoder
+
+	startTime time.Time
+}
+
+func NewRecordingEventStream(src eventstream.EventStream, dst io.Writer) *RecordingEventStream {
+	return &RecordingEventStream{
+		src:     src,
+		encoder: json.NewEncoder(dst),
+	}
+}
+
+func (stream *RecordingEventStream) NextEvent() (atc.Event, error) {
+	ev, err := stream.src.NextEvent()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if stream.startTime.IsZero() {
+		stream.startTime = now
+	}
+
+	err = stream.encoder.Encode(recordedEvent{
+		Offset: now.Sub(stream.startTime),
+		Event:  event.Message{Event: ev},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+func (stream *RecordingEventStream) Close() error {
+	return stream.src.Close()
+}
+
+// ReplayEventStream reads the events of a recording, waiting between them
+// as long as they were apart when recorded, divided by the speed. A speed
+// of zero replays them all without waiting.
+type ReplayEventStream struct {
+	src     io.Reader
+	decoder *json.Decoder
+	speed   float64
+
+	startTime time.Time
+}
+
+func NewReplayEventStream(src io.Reader, speed float64) *ReplayEventStream {
+	return &ReplayEventStream{
+		src:     src,
+		decoder: json.NewDecoder(src),
+		speed:   speed,
+	}
+}
+
+func (stream *ReplayEventStream) NextEvent() (atc.Event, error) {
+	var recorded recordedEvent
+	err := stream.decoder.Decode(&recorded)
+	if err != nil {
+		return nil, err
+	}
+
+	if stream.startTime.IsZero() {
+		stream.startTime = time.Now()
+	}
+
+	if stream.speed > 0 {
+		due := stream.startTime.Add(time.Duration(float64(recorded.Offset) / stream.speed))
+		time.Sleep(due.Sub(time.Now()))
+	}
+
+	return recorded.Event.Event, nil
+}
+
+func (stream *ReplayEventStream) Close() error {
+	if closer, ok := stream.src.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
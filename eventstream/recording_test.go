@@ -0,0 +1,85 @@
+package eventstream_test
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/go-concourse/concourse/eventstream/fakes"
+)
+
+var _ = Describe("Recording and replaying", func() {
+	var (
+		recording *bytes.Buffer
+		events    []atc.Event
+	)
+
+	fakeStream := func(events []atc.Event, delay time.Duration) *fakes.FakeEventStream {
+		stream := new(fakes.FakeEventStream)
+
+		stream.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			time.Sleep(delay)
+
+			ev := events[0]
+			events = events[1:]
+
+			return ev, nil
+		}
+
+		return stream
+	}
+
+	BeforeEach(func() {
+		recording = new(bytes.Buffer)
+
+		origin := event.Origin{Name: "some-task", Type: event.OriginTypeTask}
+
+		events = []atc.Event{
+			event.Status{Status: atc.StatusStarted, Time: 1},
+			event.InitializeTask{Origin: origin},
+			event.StartTask{Origin: origin, Time: 2},
+			event.Log{Payload: "hello\n", Origin: origin},
+			event.FinishTask{Origin: origin, Time: 3, ExitStatus: 4},
+			event.Status{Status: atc.StatusFailed, Time: 3},
+		}
+	})
+
+	It("passes the events through while recording them", func() {
+		stream := eventstream.NewRecordingEventStream(fakeStream(events, 0), recording)
+
+		exitStatus := eventstream.Render(gbytes.NewBuffer(), stream)
+		Expect(exitStatus).To(Equal(4))
+	})
+
+	It("replays a recording as the build was rendered", func() {
+		live := gbytes.NewBuffer()
+		eventstream.Render(live, eventstream.NewRecordingEventStream(fakeStream(events, 0), recording))
+
+		replayed := gbytes.NewBuffer()
+		exitStatus := eventstream.Render(replayed, eventstream.NewReplayEventStream(recording, 0))
+
+		Expect(exitStatus).To(Equal(4))
+		Expect(replayed.Contents()).To(Equal(live.Contents()))
+	})
+
+	It("replays the events as far apart as they were received, divided by the speed", func() {
+		stream := eventstream.NewRecordingEventStream(fakeStream(events, 50*time.Millisecond), recording)
+		eventstream.Render(gbytes.NewBuffer(), stream)
+
+		replayStarted := time.Now()
+		eventstream.Render(gbytes.NewBuffer(), eventstream.NewReplayEventStream(bytes.NewBuffer(recording.Bytes()), 2))
+		Expect(time.Since(replayStarted)).To(BeNumerically("~", 125*time.Millisecond, 50*time.Millisecond))
+	})
+})
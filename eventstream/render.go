@@ -1,19 +1,80 @@
 package eventstream
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"strings"
+	"regexp"
+	"time"
 
+	"github.com/concourse/atc"
 	"github.com/concourse/atc/event"
-	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse/eventstream"
-	"github.com/fatih/color"
 )
 
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Renderer writes out the events of a build as they are read. Working out
+// the exit status is left to RenderWith, so that it is the same whatever
+// the format.
+type Renderer interface {
+	RenderEvent(atc.Event)
+	RenderError(error)
+}
+
+const (
+	AbsoluteTimestamps = "absolute"
+	RelativeTimestamps = "relative"
+)
+
+// RenderOptions tweak how the text renderer prints a build; JSON always
+// includes everything.
+type RenderOptions struct {
+	// Timestamps prefixes each line of the logs with the time it was
+	// printed, if its event says, or else received, either
+	// AbsoluteTimestamps or RelativeTimestamps to when the build started. No
+	// timestamps are printed when it is empty.
+	Timestamps string
+
+	// StartTime is when the build started, for relative timestamps when
+	// attaching to a build whose started event may not be seen.
+	StartTime time.Time
+
+	// Summary prints a table of the build's steps, with how long each took,
+	// once it finishes.
+	Summary bool
+
+	// Grep only prints the lines of the logs that match it, along with
+	// Context lines either side of each match.
+	Grep    *regexp.Regexp
+	Context int
+
+	// Highlight colors the parts of the logs that match it. When it is nil,
+	// whatever matches Grep is highlighted instead.
+	Highlight *regexp.Regexp
+}
+
+// NewRenderer returns the renderer for the given format, which is either
+// TextFormat or JSONFormat.
+func NewRenderer(format string, dst io.Writer, options RenderOptions) Renderer {
+	if format == JSONFormat {
+		return NewJSONRenderer(dst)
+	}
+
+	return NewTextRenderer(dst, options)
+}
+
 func Render(dst io.Writer, src eventstream.EventStream) int {
-	var buildConfig event.TaskConfig
+	return RenderWith(NewTextRenderer(dst, RenderOptions{}), src)
+}
 
+// RenderWith feeds the events from src to the renderer until the build
+// finishes, and returns the exit status of its task, or else one for its
+// final status.
+func RenderWith(renderer Renderer, src eventstream.EventStream) int {
 	exitStatus := 0
 
 	for {
@@ -22,72 +83,63 @@ func Render(dst io.Writer, src eventstream.EventStream) int {
 			if err == io.EOF {
 				return exitStatus
 			} else {
-				fmt.Fprintf(dst, "failed to parse next event: %s\n", err)
+				renderer.RenderError(fmt.Errorf("failed to parse next event: %s", err))
 				return 255
 			}
 		}
 
-		switch e := ev.(type) {
-		case event.Log:
-			fmt.Fprintf(dst, "%s", e.Payload)
-
-		case event.InitializeTask:
-			buildConfig = e.TaskConfig
-
-			if buildConfig.Image != "" {
-				fmt.Fprintf(dst, "\x1b[1minitializing with %s\x1b[0m\n", buildConfig.Image)
-			} else {
-				fmt.Fprintf(dst, "\x1b[1minitializing\x1b[0m\n")
-			}
-
-		case event.StartTask:
-			argv := strings.Join(append([]string{buildConfig.Run.Path}, buildConfig.Run.Args...), " ")
-			fmt.Fprintf(dst, "\x1b[1mrunning %s\x1b[0m\n", argv)
+		renderer.RenderEvent(ev)
 
+		switch e := ev.(type) {
 		case event.FinishTask:
 			exitStatus = e.ExitStatus
 
-		case event.Error:
-			errCol := ui.ErroredColor.SprintFunc()
-			fmt.Fprintf(dst, "%s\n", errCol(e.Message))
-
 		case event.Status:
-			var printColor *color.Color
-
-			switch e.Status {
-			case "started":
+			if e.Status == atc.StatusStarted {
 				continue
-			case "succeeded":
-				printColor = ui.SucceededColor
-			case "failed":
-				printColor = ui.FailedColor
-
-				if exitStatus == 0 {
-					exitStatus = 1
-				}
-			case "errored":
-				printColor = ui.ErroredColor
-
-				if exitStatus == 0 {
-					exitStatus = 2
-				}
-			case "aborted":
-				printColor = ui.AbortedColor
-
-				if exitStatus == 0 {
-					exitStatus = 3
-				}
-			default:
-				fmt.Fprintf(dst, "unknown status: %s", e.Status)
-				return 255
 			}
 
-			printColorFunc := printColor.SprintFunc()
-			fmt.Fprintf(dst, "%s\n", printColorFunc(e.Status))
+			return statusExitCode(e.Status, exitStatus)
+		}
+	}
+}
 
-			return exitStatus
+func statusExitCode(status atc.BuildStatus, exitStatus int) int {
+	switch status {
+	case atc.StatusSucceeded:
+		return exitStatus
+	case atc.StatusFailed:
+		if exitStatus == 0 {
+			return 1
+		}
+	case atc.StatusErrored:
+		if exitStatus == 0 {
+			return 2
 		}
+	case atc.StatusAborted:
+		if exitStatus == 0 {
+			return 3
+		}
+	default:
+		return 255
+	}
+
+	return exitStatus
+}
+
+// the fields that events have in common, when they have them
+type eventHeader struct {
+	Time   int64            `json:"time"`
+	Origin *json.RawMessage `json:"origin"`
+}
+
+func headerOf(ev atc.Event) eventHeader {
+	var header eventHeader
+
+	payload, err := json.Marshal(ev)
+	if err == nil {
+		json.Unmarshal(payload, &header)
 	}
 
-	return 255
+	return header
 }
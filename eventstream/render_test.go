@@ -2,6 +2,7 @@ package eventstream_test
 
 import (
 	"io"
+	"regexp"
 	"time"
 
 	"github.com/fatih/color"
@@ -24,11 +25,14 @@ var _ = Describe("V1.0 Renderer", func() {
 
 		receivedEvents chan<- atc.Event
 
+		options eventstream.RenderOptions
+
 		exitStatus int
 	)
 
 	BeforeEach(func() {
 		color.NoColor = false
+		options = eventstream.RenderOptions{}
 		out = gbytes.NewBuffer()
 		stream = new(fakes.FakeEventStream)
 
@@ -46,7 +50,7 @@ var _ = Describe("V1.0 Renderer", func() {
 	})
 
 	JustBeforeEach(func() {
-		exitStatus = eventstream.Render(out, stream)
+		exitStatus = eventstream.RenderWith(eventstream.NewTextRenderer(out, options), stream)
 	})
 
 	Context("when a Log event is received", func() {
@@ -61,6 +65,248 @@ var _ = Describe("V1.0 Renderer", func() {
 		})
 	})
 
+	Context("with timestamps", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.Status{
+				Status: atc.StatusStarted,
+				Time:   time.Now().Add(-90 * time.Second).Unix(),
+			}
+
+			receivedEvents <- event.Log{Payload: "hel"}
+			receivedEvents <- event.Log{Payload: "lo\nwor"}
+			receivedEvents <- event.Log{Payload: "ld\n"}
+		})
+
+		Context("that are absolute", func() {
+			BeforeEach(func() {
+				options.Timestamps = eventstream.AbsoluteTimestamps
+			})
+
+			It("prefixes each line with the time, even when it is split across events", func() {
+				Expect(out).To(gbytes.Say(`\d\d:\d\d:\d\d\x1b\[0m hello\n`))
+				Expect(out).To(gbytes.Say(`\d\d:\d\d:\d\d\x1b\[0m world\n`))
+			})
+		})
+
+		Context("that are relative", func() {
+			BeforeEach(func() {
+				options.Timestamps = eventstream.RelativeTimestamps
+			})
+
+			It("prefixes each line with the time since the build started", func() {
+				Expect(out).To(gbytes.Say(`00:01:3\d\x1b\[0m hello\n`))
+				Expect(out).To(gbytes.Say(`00:01:3\d\x1b\[0m world\n`))
+			})
+		})
+
+		Context("that are relative, when attaching after the build started", func() {
+			BeforeEach(func() {
+				options.Timestamps = eventstream.RelativeTimestamps
+				options.StartTime = time.Now().Add(-90 * time.Second)
+
+				// drop the started event, which has already gone by
+				_, err := stream.NextEventStub()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("prefixes each line with the time since the given start time", func() {
+				Expect(out).To(gbytes.Say(`00:01:3\d\x1b\[0m hello\n`))
+				Expect(out).To(gbytes.Say(`00:01:3\d\x1b\[0m world\n`))
+			})
+		})
+
+		Context("when a line is unfinished when another event is received", func() {
+			BeforeEach(func() {
+				options.Timestamps = eventstream.AbsoluteTimestamps
+
+				receivedEvents <- event.Log{Payload: "partial"}
+				receivedEvents <- event.Error{Message: "oh no!"}
+			})
+
+			It("ends the line first", func() {
+				Expect(out.Contents()).To(ContainSubstring("partial\n" + ui.ErroredColor.SprintFunc()("oh no!")))
+			})
+		})
+	})
+
+	Context("with a pattern to grep for", func() {
+		highlighted := func(match string) string {
+			return color.New(color.FgBlack, color.BgYellow).Sprint(match)
+		}
+
+		BeforeEach(func() {
+			options.Grep = regexp.MustCompile("ERR")
+
+			receivedEvents <- event.Log{Payload: "one\nERR two\n\x1b[31mthr\x1b[0mee\nfour\n"}
+			receivedEvents <- event.Log{Payload: "five\nE"}
+			receivedEvents <- event.Log{Payload: "RR six\nseven"}
+			receivedEvents <- event.Status{Status: atc.StatusFailed}
+		})
+
+		It("prints only the lines that match, even when they are split across events, with the matches highlighted", func() {
+			Expect(string(out.Contents())).To(Equal(
+				highlighted("ERR") + " two\n" +
+					"\x1b[2m--\x1b[0m\n" +
+					highlighted("ERR") + " six\n" +
+					ui.FailedColor.Sprint("failed") + "\n",
+			))
+		})
+
+		It("exits with the build's status", func() {
+			Expect(exitStatus).To(Equal(1))
+		})
+
+		Context("with context", func() {
+			BeforeEach(func() {
+				options.Context = 1
+			})
+
+			It("prints the lines either side of each match too", func() {
+				Expect(string(out.Contents())).To(Equal(
+					"one\n" +
+						highlighted("ERR") + " two\n" +
+						"\x1b[31mthr\x1b[0mee\n" +
+						"\x1b[2m--\x1b[0m\n" +
+						"five\n" +
+						highlighted("ERR") + " six\n" +
+						"seven\n" +
+						ui.FailedColor.Sprint("failed") + "\n",
+				))
+			})
+		})
+
+		Context("when the match is split by color codes", func() {
+			BeforeEach(func() {
+				options.Grep = regexp.MustCompile("three")
+			})
+
+			It("still prints the line", func() {
+				Expect(string(out.Contents())).To(HavePrefix("\x1b[31mthr\x1b[0mee\n"))
+			})
+		})
+	})
+
+	Context("with a pattern to highlight", func() {
+		BeforeEach(func() {
+			options.Highlight = regexp.MustCompile("o+")
+
+			receivedEvents <- event.Log{Payload: "foo\nb"}
+			receivedEvents <- event.Log{Payload: "oo\n"}
+		})
+
+		It("prints every line, with the matches highlighted", func() {
+			highlight := color.New(color.FgBlack, color.BgYellow).Sprint("oo")
+			Expect(string(out.Contents())).To(Equal("f" + highlight + "\nb" + highlight + "\n"))
+		})
+	})
+
+	Context("when events from several steps are received", func() {
+		BeforeEach(func() {
+			getOrigin := event.Origin{Name: "some-input", Type: event.OriginTypeGet}
+			taskOrigin := event.Origin{Name: "some-task", Type: event.OriginTypeTask}
+
+			receivedEvents <- event.InitializeGet{Origin: getOrigin}
+			receivedEvents <- event.Log{Payload: "fetching\n", Origin: getOrigin}
+			receivedEvents <- event.FinishGet{Origin: getOrigin}
+
+			taskOrigin.Source = event.OriginSourceStdout
+			receivedEvents <- event.Log{Payload: "out\n", Origin: taskOrigin}
+
+			taskOrigin.Source = event.OriginSourceStderr
+			receivedEvents <- event.Log{Payload: "err\n", Origin: taskOrigin}
+		})
+
+		It("prints a header for each step before its logs", func() {
+			Expect(string(out.Contents())).To(Equal(
+				"\x1b[1mget: some-input\x1b[0m\n" +
+					"fetching\n" +
+					"\x1b[1mtask: some-task\x1b[0m\n" +
+					"out\n" +
+					"err\n",
+			))
+		})
+	})
+
+	Context("when a FinishGet event is received", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.FinishGet{
+				Origin:         event.Origin{Name: "some-input", Type: event.OriginTypeGet},
+				FetchedVersion: atc.Version{"ref": "abc123"},
+				FetchedMetadata: []atc.MetadataField{
+					{Name: "author", Value: "someone"},
+				},
+			}
+		})
+
+		It("prints the version it fetched and its metadata", func() {
+			Expect(out).To(gbytes.Say(`fetched ref=abc123\n`))
+			Expect(out).To(gbytes.Say(`author:.* someone\n`))
+		})
+	})
+
+	Context("when a FinishPut event is received", func() {
+		Context("for a put that succeeded", func() {
+			BeforeEach(func() {
+				receivedEvents <- event.FinishPut{
+					Origin:         event.Origin{Name: "some-output", Type: event.OriginTypePut},
+					CreatedVersion: atc.Version{"version": "1.2.3"},
+				}
+			})
+
+			It("prints the version it created", func() {
+				Expect(out).To(gbytes.Say(`created version=1.2.3\n`))
+			})
+		})
+
+		Context("for a put that failed", func() {
+			BeforeEach(func() {
+				receivedEvents <- event.FinishPut{
+					Origin:     event.Origin{Name: "some-output", Type: event.OriginTypePut},
+					ExitStatus: 1,
+				}
+			})
+
+			It("prints its exit status in red", func() {
+				Expect(out.Contents()).To(ContainSubstring(ui.FailedColor.SprintFunc()("exit status 1") + "\n"))
+			})
+		})
+	})
+
+	Context("when an event of an unknown type is received", func() {
+		BeforeEach(func() {
+			receivedEvents <- unknownEvent{}
+		})
+
+		It("says that it was not handled", func() {
+			Expect(out).To(gbytes.Say(`unhandled event: some-unknown-event \(version 42.0\)`))
+		})
+	})
+
+	Context("with a summary", func() {
+		BeforeEach(func() {
+			options.Summary = true
+
+			getOrigin := event.Origin{Name: "some-input", Type: event.OriginTypeGet}
+			taskOrigin := event.Origin{Name: "some-task", Type: event.OriginTypeTask}
+
+			startTime := time.Now().Add(-2 * time.Minute)
+
+			receivedEvents <- event.InitializeGet{Origin: getOrigin}
+			receivedEvents <- event.FinishGet{Origin: getOrigin}
+			receivedEvents <- event.StartTask{Origin: taskOrigin, Time: startTime.Unix()}
+			receivedEvents <- event.FinishTask{Origin: taskOrigin, Time: startTime.Add(90 * time.Second).Unix(), ExitStatus: 1}
+			receivedEvents <- event.InitializePut{Origin: event.Origin{Name: "some-output", Type: event.OriginTypePut}}
+			receivedEvents <- event.Status{Status: atc.StatusAborted}
+		})
+
+		It("prints each step's type, status, start time and duration after the build's status", func() {
+			Expect(out).To(gbytes.Say(`aborted`))
+			Expect(out).To(gbytes.Say(`some-input\s+get\s+succeeded\s+\d\d:\d\d:\d\d\s+0s`))
+			Expect(out).To(gbytes.Say(`some-task\s+task\s+failed\s+\d\d:\d\d:\d\d\s+1m30s`))
+			Expect(out).To(gbytes.Say(`some-output\s+put\s+aborted`))
+		})
+	})
+
 	Context("when an Error event is received", func() {
 		BeforeEach(func() {
 			receivedEvents <- event.Error{
@@ -197,3 +443,8 @@ var _ = Describe("V1.0 Renderer", func() {
 		})
 	})
 })
+
+type unknownEvent struct{}
+
+func (unknownEvent) EventType() atc.EventType  { return "some-unknown-event" }
+func (unknownEvent) Version() atc.EventVersion { return "42.0" }
@@ -0,0 +1,165 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/tedsuo/rata"
+	"github.com/vito/go-sse/sse"
+)
+
+// ConnectFunc opens a build's event stream after the event with the given
+// ID, or from the start if it is empty.
+type ConnectFunc func(lastEventID string) (eventstream.EventStream, error)
+
+// ResumingEventStream reconnects when its connection drops, picking up after
+// the last event it read so that none are repeated. It backs off
+// exponentially between attempts, and gives up once it has been unable to
+// read an event for the GiveUpAfter duration.
+type ResumingEventStream struct {
+	GiveUpAfter    time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Notify is told about each reconnection attempt, if set
+	Notify io.Writer
+
+	connect ConnectFunc
+	stream  eventstream.EventStream
+
+	// events are numbered in order from zero, so the number read so far
+	// tells where to resume from
+	eventsRead int
+}
+
+// ConnectResuming connects to the stream, returning an error straight away
+// if it cannot, as the build might not exist.
+func ConnectResuming(connect ConnectFunc, giveUpAfter time.Duration) (*ResumingEventStream, error) {
+	stream, err := connect("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResumingEventStream{
+		GiveUpAfter:    giveUpAfter,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+
+		connect: connect,
+		stream:  stream,
+	}, nil
+}
+
+func (stream *ResumingEventStream) NextEvent() (atc.Event, error) {
+	var lostAt time.Time
+	var err error
+
+	backoff := stream.InitialBackoff
+
+	for {
+		if stream.stream != nil {
+			var ev atc.Event
+			ev, err = stream.stream.NextEvent()
+			if err == nil {
+				stream.eventsRead++
+				return ev, nil
+			}
+
+			if !isTransient(err) {
+				return nil, err
+			}
+
+			stream.stream.Close()
+			stream.stream = nil
+		}
+
+		if lostAt.IsZero() {
+			lostAt = time.Now()
+		}
+
+		remaining := stream.GiveUpAfter - time.Since(lostAt)
+		if remaining <= 0 {
+			return nil, err
+		}
+
+		if backoff > remaining {
+			backoff = remaining
+		}
+
+		if stream.Notify != nil {
+			fmt.Fprintf(stream.Notify, "lost connection to the build's events; reconnecting in %s...\n", backoff)
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > stream.MaxBackoff {
+			backoff = stream.MaxBackoff
+		}
+
+		stream.stream, err = stream.connect(stream.lastEventID())
+		if err != nil && !isTransient(err) {
+			return nil, err
+		}
+	}
+}
+
+func (stream *ResumingEventStream) Close() error {
+	if stream.stream == nil {
+		return nil
+	}
+
+	return stream.stream.Close()
+}
+
+func (stream *ResumingEventStream) lastEventID() string {
+	if stream.eventsRead == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(stream.eventsRead - 1)
+}
+
+func isTransient(err error) bool {
+	if err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	_, isNetError := err.(net.Error)
+	return isNetError
+}
+
+// BuildEventsConnector connects to a build's events with the client,
+// resuming after the last event ID with the Last-Event-ID header.
+func BuildEventsConnector(client concourse.Client, buildID string) ConnectFunc {
+	return func(lastEventID string) (eventstream.EventStream, error) {
+		if lastEventID == "" {
+			return client.BuildEvents(buildID)
+		}
+
+		requestGenerator := rata.NewRequestGenerator(client.URL(), atc.Routes)
+
+		request, err := requestGenerator.CreateRequest(atc.BuildEvents, rata.Params{"build_id": buildID}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Set("Last-Event-ID", lastEventID)
+
+		source, err := sse.Connect(client.HTTPClient(), time.Second, func() *http.Request {
+			return request
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return eventstream.NewSSEEventStream(source), nil
+	}
+}
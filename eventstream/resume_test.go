@@ -0,0 +1,194 @@
+package eventstream_test
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	goeventstream "github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/concourse/go-concourse/concourse/eventstream/fakes"
+)
+
+var _ = Describe("ResumingEventStream", func() {
+	var (
+		connections []*fakes.FakeEventStream
+		connectErrs []error
+
+		lastEventIDs []string
+
+		stream *eventstream.ResumingEventStream
+	)
+
+	streamOf := func(events ...atc.Event) *fakes.FakeEventStream {
+		fake := new(fakes.FakeEventStream)
+
+		fake.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			ev := events[0]
+			events = events[1:]
+
+			return ev, nil
+		}
+
+		return fake
+	}
+
+	droppingAfter := func(events ...atc.Event) *fakes.FakeEventStream {
+		fake := streamOf(events...)
+		next := fake.NextEventStub
+
+		fake.NextEventStub = func() (atc.Event, error) {
+			ev, err := next()
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			return ev, err
+		}
+
+		return fake
+	}
+
+	BeforeEach(func() {
+		connections = nil
+		connectErrs = nil
+		lastEventIDs = nil
+	})
+
+	JustBeforeEach(func() {
+		connect := func(lastEventID string) (goeventstream.EventStream, error) {
+			lastEventIDs = append(lastEventIDs, lastEventID)
+
+			var err error
+			if len(connectErrs) > 0 {
+				err = connectErrs[0]
+				connectErrs = connectErrs[1:]
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			connection := connections[0]
+			connections = connections[1:]
+
+			return connection, nil
+		}
+
+		var err error
+		stream, err = eventstream.ConnectResuming(connect, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		stream.InitialBackoff = time.Millisecond
+		stream.MaxBackoff = 10 * time.Millisecond
+	})
+
+	readAll := func() ([]atc.Event, error) {
+		events := []atc.Event{}
+
+		for {
+			ev, err := stream.NextEvent()
+			if err != nil {
+				return events, err
+			}
+
+			events = append(events, ev)
+		}
+	}
+
+	Context("when the connection drops", func() {
+		var dropped *fakes.FakeEventStream
+
+		BeforeEach(func() {
+			dropped = droppingAfter(event.Log{Payload: "one"}, event.Log{Payload: "two"})
+
+			connections = []*fakes.FakeEventStream{
+				dropped,
+				streamOf(event.Log{Payload: "three"}),
+			}
+		})
+
+		It("reconnects after the last event it read, and carries on", func() {
+			events, err := readAll()
+			Expect(err).To(Equal(io.EOF))
+
+			Expect(events).To(Equal([]atc.Event{
+				event.Log{Payload: "one"},
+				event.Log{Payload: "two"},
+				event.Log{Payload: "three"},
+			}))
+
+			Expect(lastEventIDs).To(Equal([]string{"", "1"}))
+		})
+
+		It("closes the connection that dropped", func() {
+			readAll()
+
+			Expect(dropped.CloseCallCount()).To(Equal(1))
+		})
+
+		Context("and reconnecting fails for a while", func() {
+			BeforeEach(func() {
+				connectErrs = []error{nil, &timeoutError{}, &timeoutError{}}
+			})
+
+			It("keeps trying", func() {
+				events, err := readAll()
+				Expect(err).To(Equal(io.EOF))
+				Expect(events).To(HaveLen(3))
+
+				Expect(lastEventIDs).To(Equal([]string{"", "1", "1", "1"}))
+			})
+		})
+
+		Context("and reconnecting keeps failing", func() {
+			BeforeEach(func() {
+				connectErrs = []error{nil}
+				for i := 0; i < 1000; i++ {
+					connectErrs = append(connectErrs, &timeoutError{})
+				}
+			})
+
+			It("gives up after the deadline", func() {
+				started := time.Now()
+
+				events, err := readAll()
+				Expect(err).To(Equal(&timeoutError{}))
+				Expect(events).To(HaveLen(2))
+
+				Expect(time.Since(started)).To(BeNumerically(">=", time.Second))
+			})
+		})
+	})
+
+	Context("when reading fails for another reason", func() {
+		BeforeEach(func() {
+			failing := new(fakes.FakeEventStream)
+			failing.NextEventReturns(nil, errors.New("unknown event type"))
+
+			connections = []*fakes.FakeEventStream{failing}
+		})
+
+		It("returns the error without reconnecting", func() {
+			_, err := readAll()
+			Expect(err).To(MatchError("unknown event type"))
+
+			Expect(lastEventIDs).To(Equal([]string{""}))
+		})
+	})
+})
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
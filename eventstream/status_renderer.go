@@ -0,0 +1,39 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+)
+
+// StatusRenderer prints only the build's final status, for scripts that
+// wait on a build without wanting its logs.
+type StatusRenderer struct {
+	dst io.Writer
+}
+
+func NewStatusRenderer(dst io.Writer) *StatusRenderer {
+	return &StatusRenderer{dst: dst}
+}
+
+func (renderer *StatusRenderer) RenderEvent(ev atc.Event) {
+	status, ok := ev.(event.Status)
+	if !ok || status.Status == atc.StatusStarted {
+		return
+	}
+
+	statusColor := ui.StatusColor(string(status.Status))
+	if statusColor == nil {
+		fmt.Fprintf(renderer.dst, "unknown status: %s\n", status.Status)
+		return
+	}
+
+	fmt.Fprintf(renderer.dst, "%s\n", statusColor.SprintFunc()(status.Status))
+}
+
+func (renderer *StatusRenderer) RenderError(err error) {
+	fmt.Fprintf(renderer.dst, "%s\n", err)
+}
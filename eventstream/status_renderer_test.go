@@ -0,0 +1,62 @@
+package eventstream_test
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse/eventstream/fakes"
+)
+
+var _ = Describe("Status Renderer", func() {
+	var (
+		out    *gbytes.Buffer
+		stream *fakes.FakeEventStream
+
+		exitStatus int
+	)
+
+	BeforeEach(func() {
+		out = gbytes.NewBuffer()
+		stream = new(fakes.FakeEventStream)
+
+		events := []atc.Event{
+			event.Status{Status: atc.StatusStarted},
+			event.InitializeTask{},
+			event.Log{Payload: "hello\n"},
+			event.Error{Message: "oh no!"},
+			event.FinishTask{ExitStatus: 42},
+			event.Status{Status: atc.StatusFailed},
+		}
+
+		stream.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			ev := events[0]
+			events = events[1:]
+
+			return ev, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		exitStatus = eventstream.RenderWith(eventstream.NewStatusRenderer(out), stream)
+	})
+
+	It("prints only the final status", func() {
+		Expect(string(out.Contents())).To(Equal(ui.FailedColor.SprintFunc()("failed") + "\n"))
+	})
+
+	It("exits as the text renderer does", func() {
+		Expect(exitStatus).To(Equal(42))
+	})
+})
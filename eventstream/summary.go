@@ -0,0 +1,125 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+// buildSummary keeps track of when each step of a build ran, and how it
+// finished.
+type buildSummary struct {
+	steps []*stepSummary
+}
+
+type stepSummary struct {
+	origin event.Origin
+	status string
+
+	// the times the events themselves carry, where they have them
+	startTime time.Time
+	endTime   time.Time
+
+	// the times the events were received, for steps with none of their own
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+func (summary *buildSummary) record(origin event.Origin, ev atc.Event) {
+	step := summary.step(origin)
+
+	now := time.Now()
+	if step.firstSeen.IsZero() {
+		step.firstSeen = now
+	}
+
+	step.lastSeen = now
+
+	if eventTime := headerOf(ev).Time; eventTime != 0 {
+		t := time.Unix(eventTime, 0)
+
+		if step.startTime.IsZero() {
+			step.startTime = t
+		}
+
+		step.endTime = t
+	}
+
+	switch e := ev.(type) {
+	case event.FinishTask:
+		step.status = exitStatusResult(e.ExitStatus)
+	case event.FinishGet:
+		step.status = exitStatusResult(e.ExitStatus)
+	case event.FinishPut:
+		step.status = exitStatusResult(e.ExitStatus)
+	case event.Error:
+		step.status = string(atc.StatusErrored)
+	}
+}
+
+func (summary *buildSummary) step(origin event.Origin) *stepSummary {
+	for _, step := range summary.steps {
+		if sameStep(step.origin, origin) {
+			return step
+		}
+	}
+
+	step := &stepSummary{origin: origin}
+	summary.steps = append(summary.steps, step)
+
+	return step
+}
+
+// render prints a table of the steps in the order they started. Steps that
+// never finished are given the status of the build.
+func (summary *buildSummary) render(dst io.Writer, buildStatus atc.BuildStatus) error {
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "step", Color: color.New(color.Bold)},
+			{Contents: "type", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+			{Contents: "start", Color: color.New(color.Bold)},
+			{Contents: "duration", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, step := range summary.steps {
+		status := step.status
+		if status == "" {
+			status = string(buildStatus)
+		}
+
+		start, end := step.startTime, step.endTime
+		if start.IsZero() {
+			start, end = step.firstSeen, step.lastSeen
+		}
+
+		duration := end.Sub(start)
+		duration -= duration % time.Second
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: step.origin.Name},
+			{Contents: string(step.origin.Type)},
+			{Contents: status, Color: ui.StatusColor(status)},
+			{Contents: start.Local().Format("15:04:05")},
+			{Contents: duration.String()},
+		})
+	}
+
+	fmt.Fprintln(dst, "")
+
+	return table.Render(dst)
+}
+
+func exitStatusResult(exitStatus int) string {
+	if exitStatus == 0 {
+		return string(atc.StatusSucceeded)
+	}
+
+	return string(atc.StatusFailed)
+}
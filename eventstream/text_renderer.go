@@ -0,0 +1,400 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+var faintColor = color.New(color.Faint)
+
+var highlightColor = color.New(color.FgBlack, color.BgYellow)
+
+// TextRenderer prints the build's logs, with its steps and final status
+// highlighted, for a terminal.
+type TextRenderer struct {
+	dst     io.Writer
+	options RenderOptions
+
+	buildConfig event.TaskConfig
+
+	// when the build started, for relative timestamps: from the options or
+	// the build's started event, or else the time of the first event
+	startTime time.Time
+
+	// the step whose events were rendered last
+	step event.Origin
+
+	summary buildSummary
+
+	// whether the last log payload ended partway through a line
+	midLine bool
+
+	// with Grep or Highlight, logs are reassembled into whole lines before
+	// they are printed, starting with the rest of a line not yet received
+	partial     string
+	partialTime time.Time
+
+	// the lines that are held back in case one after them matches Grep
+	before []logLine
+
+	// how many lines after the last match are still to be printed
+	after int
+
+	// whether lines have been left out since a match was printed
+	matched bool
+	skipped bool
+}
+
+type logLine struct {
+	text string
+	time time.Time
+}
+
+func NewTextRenderer(dst io.Writer, options RenderOptions) *TextRenderer {
+	return &TextRenderer{
+		dst:       dst,
+		options:   options,
+		startTime: options.StartTime,
+	}
+}
+
+func (renderer *TextRenderer) RenderEvent(ev atc.Event) {
+	dst := renderer.dst
+
+	if renderer.startTime.IsZero() {
+		renderer.startTime = eventTime(ev)
+	}
+
+	if origin, ok := originOf(ev); ok && origin.Name != "" {
+		if !sameStep(origin, renderer.step) {
+			renderer.endLine()
+			renderer.step = origin
+
+			fmt.Fprintf(dst, "\x1b[1m%s: %s\x1b[0m\n", origin.Type, origin.Name)
+		}
+
+		renderer.summary.record(origin, ev)
+	}
+
+	if log, ok := ev.(event.Log); ok {
+		renderer.renderLog(log)
+		return
+	}
+
+	// anything else starts on a line of its own
+	renderer.endLine()
+
+	switch e := ev.(type) {
+	case event.InitializeTask:
+		renderer.buildConfig = e.TaskConfig
+
+		if e.TaskConfig.Image != "" {
+			fmt.Fprintf(dst, "\x1b[1minitializing with %s\x1b[0m\n", e.TaskConfig.Image)
+		} else {
+			fmt.Fprintf(dst, "\x1b[1minitializing\x1b[0m\n")
+		}
+
+	case event.StartTask:
+		run := renderer.buildConfig.Run
+		argv := strings.Join(append([]string{run.Path}, run.Args...), " ")
+		fmt.Fprintf(dst, "\x1b[1mrunning %s\x1b[0m\n", argv)
+
+	case event.FinishTask, event.InitializeGet, event.InitializePut:
+		// the step's header and logs say all there is to say
+
+	case event.FinishGet:
+		renderer.renderResourceResult("fetched", e.ExitStatus, e.FetchedVersion, e.FetchedMetadata)
+
+	case event.FinishPut:
+		renderer.renderResourceResult("created", e.ExitStatus, e.CreatedVersion, e.CreatedMetadata)
+
+	case event.Error:
+		errCol := ui.ErroredColor.SprintFunc()
+		fmt.Fprintf(dst, "%s\n", errCol(e.Message))
+
+	case event.Status:
+		var printColor *color.Color
+
+		switch e.Status {
+		case atc.StatusStarted:
+			if e.Time != 0 {
+				renderer.startTime = time.Unix(e.Time, 0)
+			}
+
+			return
+		case atc.StatusSucceeded:
+			printColor = ui.SucceededColor
+		case atc.StatusFailed:
+			printColor = ui.FailedColor
+		case atc.StatusErrored:
+			printColor = ui.ErroredColor
+		case atc.StatusAborted:
+			printColor = ui.AbortedColor
+		default:
+			fmt.Fprintf(dst, "unknown status: %s", e.Status)
+			return
+		}
+
+		printColorFunc := printColor.SprintFunc()
+		fmt.Fprintf(dst, "%s\n", printColorFunc(e.Status))
+
+		if renderer.options.Summary {
+			err := renderer.summary.render(dst, e.Status)
+			if err != nil {
+				fmt.Fprintf(dst, "failed to print summary: %s\n", err)
+			}
+		}
+
+	default:
+		faintColor.Fprintf(dst, "unhandled event: %s (version %s)\n", ev.EventType(), ev.Version())
+	}
+}
+
+func (renderer *TextRenderer) RenderError(err error) {
+	renderer.endLine()
+	fmt.Fprintf(renderer.dst, "%s\n", err)
+}
+
+// renderLog prefixes each line that the payload starts with a timestamp. A
+// line may be split across payloads, so the rest of one is written as it
+// comes, without another prefix.
+func (renderer *TextRenderer) renderLog(log event.Log) {
+	if log.Payload == "" {
+		return
+	}
+
+	if renderer.options.Grep != nil || renderer.options.Highlight != nil {
+		renderer.bufferLog(log.Payload, eventTime(log))
+		return
+	}
+
+	if renderer.options.Timestamps == "" {
+		fmt.Fprintf(renderer.dst, "%s", log.Payload)
+		renderer.midLine = !strings.HasSuffix(log.Payload, "\n")
+		return
+	}
+
+	prefix := faintColor.Sprint(renderer.timestamp(eventTime(log))) + " "
+
+	payload := log.Payload
+	for payload != "" {
+		line := payload
+		if i := strings.IndexByte(payload, '\n'); i != -1 {
+			line = payload[:i+1]
+		}
+
+		payload = payload[len(line):]
+
+		if !renderer.midLine {
+			fmt.Fprint(renderer.dst, prefix)
+		}
+
+		fmt.Fprint(renderer.dst, line)
+
+		renderer.midLine = !strings.HasSuffix(line, "\n")
+	}
+}
+
+// bufferLog splits the payload into lines, holding on to the last of them
+// if it is not yet complete.
+func (renderer *TextRenderer) bufferLog(payload string, received time.Time) {
+	for payload != "" {
+		if renderer.partial == "" {
+			renderer.partialTime = received
+		}
+
+		i := strings.IndexByte(payload, '\n')
+		if i == -1 {
+			renderer.partial += payload
+			return
+		}
+
+		line := logLine{
+			text: renderer.partial + payload[:i+1],
+			time: renderer.partialTime,
+		}
+
+		renderer.partial = ""
+		payload = payload[i+1:]
+
+		renderer.filterLine(line)
+	}
+}
+
+// filterLine prints the line if it matches Grep or is within Context lines
+// of one that does, separating groups of lines that are not contiguous with
+// "--", as grep does.
+func (renderer *TextRenderer) filterLine(line logLine) {
+	grep := renderer.options.Grep
+	if grep == nil {
+		renderer.writeLine(line)
+		return
+	}
+
+	if grep.MatchString(ui.StripANSI(line.text)) {
+		if renderer.matched && renderer.skipped {
+			fmt.Fprintln(renderer.dst, faintColor.Sprint("--"))
+		}
+
+		for _, before := range renderer.before {
+			renderer.writeLine(before)
+		}
+
+		renderer.writeLine(line)
+
+		renderer.before = nil
+		renderer.after = renderer.options.Context
+		renderer.matched = true
+		renderer.skipped = false
+		return
+	}
+
+	if renderer.after > 0 {
+		renderer.after--
+		renderer.writeLine(line)
+		return
+	}
+
+	renderer.before = append(renderer.before, line)
+	if len(renderer.before) > renderer.options.Context {
+		renderer.before = renderer.before[1:]
+		renderer.skipped = true
+	}
+}
+
+func (renderer *TextRenderer) writeLine(line logLine) {
+	text := line.text
+
+	highlight := renderer.options.Highlight
+	if highlight == nil {
+		highlight = renderer.options.Grep
+	}
+
+	if highlight != nil {
+		// leave the newline out, so that the color never spills onto the next
+		// line
+		text = highlight.ReplaceAllStringFunc(strings.TrimSuffix(text, "\n"), func(match string) string {
+			if match == "" {
+				return match
+			}
+
+			return highlightColor.Sprint(match)
+		}) + "\n"
+	}
+
+	if renderer.options.Timestamps != "" {
+		text = faintColor.Sprint(renderer.timestamp(line.time)) + " " + text
+	}
+
+	fmt.Fprint(renderer.dst, text)
+}
+
+func (renderer *TextRenderer) renderResourceResult(verb string, exitStatus int, version atc.Version, metadata []atc.MetadataField) {
+	dst := renderer.dst
+
+	if exitStatus != 0 {
+		fmt.Fprintf(dst, "%s\n", ui.FailedColor.Sprintf("exit status %d", exitStatus))
+		return
+	}
+
+	if len(version) == 0 {
+		return
+	}
+
+	fmt.Fprintf(dst, "%s %s\n", verb, formatVersion(version))
+
+	for _, field := range metadata {
+		fmt.Fprintf(dst, "  %s %s\n", faintColor.Sprint(field.Name+":"), field.Value)
+	}
+}
+
+func (renderer *TextRenderer) endLine() {
+	if renderer.partial != "" {
+		line := logLine{
+			text: renderer.partial + "\n",
+			time: renderer.partialTime,
+		}
+
+		renderer.partial = ""
+
+		renderer.filterLine(line)
+	}
+
+	if renderer.midLine {
+		fmt.Fprintln(renderer.dst)
+		renderer.midLine = false
+	}
+}
+
+// eventTime is when the event happened, if it says, or else now, when it
+// was received
+func eventTime(ev atc.Event) time.Time {
+	if t := headerOf(ev).Time; t != 0 {
+		return time.Unix(t, 0)
+	}
+
+	return time.Now()
+}
+
+func (renderer *TextRenderer) timestamp(t time.Time) string {
+	if renderer.options.Timestamps != RelativeTimestamps {
+		return t.Format("15:04:05")
+	}
+
+	seconds := int(t.Sub(renderer.startTime) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func originOf(ev atc.Event) (event.Origin, bool) {
+	switch e := ev.(type) {
+	case event.Log:
+		return e.Origin, true
+	case event.Error:
+		return e.Origin, true
+	case event.InitializeTask:
+		return e.Origin, true
+	case event.StartTask:
+		return e.Origin, true
+	case event.FinishTask:
+		return e.Origin, true
+	case event.InitializeGet:
+		return e.Origin, true
+	case event.FinishGet:
+		return e.Origin, true
+	case event.InitializePut:
+		return e.Origin, true
+	case event.FinishPut:
+		return e.Origin, true
+	}
+
+	return event.Origin{}, false
+}
+
+// the source of an origin is the stream a log came from, so it does not
+// tell steps apart
+func sameStep(a event.Origin, b event.Origin) bool {
+	return a.Name == b.Name && a.Type == b.Type
+}
+
+func formatVersion(version atc.Version) string {
+	pairs := []string{}
+	for name, value := range version {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
@@ -85,6 +86,9 @@ run:
 					Source: atc.Source{
 						"uri": atcServer.URL() + "/api/v1/pipes/some-pipe-id",
 					},
+					Params: atc.Params{
+						"compression": "gzip",
+					},
 				}),
 			}),
 			planFactory.NewPlan(atc.TaskPlan{
@@ -312,6 +316,415 @@ run: {}
 		})
 	})
 
+	Context("when compression is specified", func() {
+		BeforeEach(func() {
+			(*(*expectedPlan.Do)[0].Aggregate)[0].Get.Params = atc.Params{
+				"compression":       "gzip",
+				"compression_level": 9,
+			}
+		})
+
+		It("sends the encoding and level to the archive resource", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--compression", "gzip:9")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+
+		Context("when the encoding is unknown", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--compression", "bzip2")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("unknown compression 'bzip2'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+	})
+
+	if runtime.GOOS != "windows" {
+		Context("when running with --watch", func() {
+			var streamed chan struct{}
+
+			JustBeforeEach(func() {
+				streamed = make(chan struct{}, 10)
+
+				atcServer.RouteToHandler("GET", "/api/v1/builds/128/events",
+					func(w http.ResponseWriter, r *http.Request) {
+						streamed <- struct{}{}
+
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						err := sse.Event{
+							Name: "end",
+						}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					},
+				)
+				atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", ghttp.RespondWith(200, ""))
+			})
+
+			It("runs the build again when the inputs change, until interrupted", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--watch")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streamed, 5).Should(Receive())
+				Eventually(sess.Out, 5).Should(gbytes.Say("build 128 exited 0"))
+				Eventually(sess.Out, 5).Should(gbytes.Say("waiting for changes"))
+
+				later := time.Now().Add(time.Minute)
+				Expect(os.Chtimes(taskConfigPath, later, later)).To(Succeed())
+
+				Eventually(sess.Out, 5).Should(gbytes.Say("change detected, running again"))
+				Eventually(streamed, 5).Should(Receive())
+				Eventually(sess.Out, 5).Should(gbytes.Say("waiting for changes"))
+
+				sess.Signal(os.Interrupt)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+			})
+		})
+	}
+
+	if runtime.GOOS != "windows" {
+		Context("when running with --local", func() {
+			It("runs the task on this machine without contacting the target", func() {
+				flyCmd := exec.Command(flyPath, "e", "-c", taskConfigPath, "--local")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("initializing with ubuntu"))
+				Expect(sess.Out).To(gbytes.Say("running find ."))
+				Expect(sess.Out).To(gbytes.Say("fixture/task.yml"))
+				Expect(sess.Out).To(gbytes.Say("succeeded"))
+
+				Expect(atcServer.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("with an output", func() {
+				BeforeEach(func() {
+					err := ioutil.WriteFile(
+						taskConfigPath,
+						[]byte(`---
+platform: some-platform
+
+inputs:
+- name: fixture
+
+outputs:
+- name: some-output
+
+run:
+  path: /bin/sh
+  args: [-c, "echo $FOO > some-output/foo"]
+
+params:
+  FOO: bar
+`),
+						0644,
+					)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("copies the output back to the given path", func() {
+					outputPath := filepath.Join(tmpdir, "some-output")
+
+					flyCmd := exec.Command(flyPath, "e", "-c", taskConfigPath, "--local", "-o", "some-output="+outputPath)
+					flyCmd.Dir = buildDir
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					contents, err := ioutil.ReadFile(filepath.Join(outputPath, "foo"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("bar\n"))
+				})
+			})
+
+			Context("with --inputs-from", func() {
+				It("prints an error", func() {
+					flyCmd := exec.Command(flyPath, "e", "-c", taskConfigPath, "--local", "-j", "some-pipeline/some-job")
+					flyCmd.Dir = buildDir
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(sess.Err).Should(gbytes.Say("--local cannot be used with --inputs-from or --task"))
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+				})
+			})
+		})
+	}
+
+	Context("when running with --matrix", func() {
+		var matrixPath string
+		var uploads chan struct{}
+		var createdTags chan []string
+
+		BeforeEach(func() {
+			matrixPath = filepath.Join(tmpdir, "matrix.yml")
+
+			err := ioutil.WriteFile(matrixPath, []byte(`---
+tags:
+- [linux]
+- [windows]
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			uploads = make(chan struct{}, 2)
+			createdTags = make(chan []string, 2)
+		})
+
+		JustBeforeEach(func() {
+			nextBuildID := 201
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds",
+				func(w http.ResponseWriter, r *http.Request) {
+					var plan atc.Plan
+					err := json.NewDecoder(r.Body).Decode(&plan)
+					Expect(err).NotTo(HaveOccurred())
+
+					createdTags <- (*plan.Do)[1].Task.Tags
+
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprintf(w, `{"id":%d}`, nextBuildID)
+					nextBuildID++
+				},
+			)
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id",
+				func(w http.ResponseWriter, r *http.Request) {
+					_, err := ioutil.ReadAll(r.Body)
+					Expect(err).NotTo(HaveOccurred())
+
+					uploads <- struct{}{}
+				},
+			)
+
+			for buildID, status := range map[int]atc.BuildStatus{201: atc.StatusSucceeded, 202: atc.StatusFailed} {
+				buildID, status := buildID, status
+
+				atcServer.RouteToHandler("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						for i, e := range []atc.Event{
+							event.Log{Payload: fmt.Sprintf("hello from %d\n", buildID)},
+							event.Status{Status: status},
+						} {
+							payload, err := json.Marshal(event.Message{Event: e})
+							Expect(err).NotTo(HaveOccurred())
+
+							err = sse.Event{
+								ID:   fmt.Sprintf("%d", i),
+								Name: "event",
+								Data: payload,
+							}.Write(w)
+							Expect(err).NotTo(HaveOccurred())
+						}
+
+						err := sse.Event{
+							Name: "end",
+						}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					},
+				)
+				atcServer.RouteToHandler("GET", fmt.Sprintf("/api/v1/builds/%d", buildID),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{
+						ID:     buildID,
+						Status: string(status),
+					}),
+				)
+			}
+		})
+
+		It("runs a build per combination, prefixing their logs, and summarizes them", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--matrix", matrixPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(<-createdTags).To(Equal([]string{"linux"}))
+			Expect(<-createdTags).To(Equal([]string{"windows"}))
+
+			Eventually(uploads).Should(Receive())
+			Eventually(uploads).Should(Receive())
+
+			Expect(sess.Out).To(gbytes.Say("executing build 201: tags=linux"))
+			Expect(sess.Out).To(gbytes.Say("executing build 202: tags=windows"))
+
+			out := string(sess.Out.Contents())
+			Expect(out).To(ContainSubstring("[201 tags=linux] hello from 201\n"))
+			Expect(out).To(ContainSubstring("[202 tags=windows] hello from 202\n"))
+
+			Expect(out).To(MatchRegexp(`201\s+tags=linux\s+succeeded\s+0`))
+			Expect(out).To(MatchRegexp(`202\s+tags=windows\s+failed\s+1`))
+		})
+
+		It("writes the builds and their summary to the --log-file too", func() {
+			logPath := filepath.Join(tmpdir, "build.log")
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--matrix", matrixPath, "--log-file", logPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			logContents, err := ioutil.ReadFile(logPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			log := string(logContents)
+			Expect(log).To(ContainSubstring("executing build 201: tags=linux\n"))
+			Expect(log).To(ContainSubstring("[201 tags=linux] hello from 201\n"))
+			Expect(log).To(MatchRegexp(`202\s+tags=windows\s+failed\s+1`))
+		})
+
+		Context("when outputs are requested", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--matrix", matrixPath, "-o", "some-output="+tmpdir)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("--matrix cannot be used with --output"))
+			})
+		})
+
+		Context("when the plan is only to be printed", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--matrix", matrixPath, "--dry-run")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("--matrix cannot be used with --local, --dry-run or --print-plan"))
+			})
+		})
+
+		Context("when the events are printed as json", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--matrix", matrixPath, "--output", "json")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("--matrix cannot be used with --output json"))
+			})
+		})
+	})
+
+	Context("when running with --dry-run", func() {
+		It("prints the plan without creating pipes or a build", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--dry-run")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("name: fixture"))
+			Expect(sess.Out).To(gbytes.Say("type: archive"))
+			Expect(sess.Out).To(gbytes.Say("name: one-off"))
+
+			for _, req := range atcServer.ReceivedRequests() {
+				Expect(req.Method).NotTo(Equal("POST"))
+			}
+		})
+
+		Context("with --print-plan=json", func() {
+			It("prints the plan as json", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--print-plan=json")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				var plan atc.Plan
+				err = json.Unmarshal(sess.Out.Contents(), &plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect((*plan.Do)[1].Task.Name).To(Equal("one-off"))
+			})
+		})
+
+		Context("when the build config is invalid", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(taskConfigPath, []byte("---\nrun: {}\n"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("prints the failure and exits 1", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--dry-run")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("missing"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+	})
+
 	Context("when invalid inputs are passed", func() {
 		It("prints an error", func() {
 			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-i", "fixture=.", "-i", "evan=.")
@@ -402,6 +815,47 @@ run:
 		})
 	})
 
+	Context("when an image is specified", func() {
+		BeforeEach(func() {
+			(*expectedPlan.Do)[1].Task.Config.Image = "docker:///some/image"
+		})
+
+		It("overrides the task's image", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--image", "docker:///some/image")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+
+		Context("along with --image-from", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--image", "docker:///some/image", "--image-from", "some-pipeline/some-job:some-resource")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("only one of --image and --image-from may be specified"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+	})
+
 	Context("when running with --privileged", func() {
 		BeforeEach(func() {
 			(*expectedPlan.Do)[1].Task.Privileged = true
@@ -474,6 +928,110 @@ run:
 		})
 	})
 
+	Context("when the task config is templated", func() {
+		BeforeEach(func() {
+			err := ioutil.WriteFile(
+				taskConfigPath,
+				[]byte(`---
+platform: {{platform}}
+
+image: {{image}}
+
+inputs:
+- name: fixture
+
+params:
+  FOO: bar
+  BAZ: buzz
+  X: 1
+
+run:
+  path: find
+  args: [.]
+`),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fills in variables from flags and files", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			varsPath := filepath.Join(tmpdir, "vars.yml")
+			err := ioutil.WriteFile(varsPath, []byte("platform: some-platform\nimage: overridden\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-l", varsPath, "-v", "image=ubuntu")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+
+		It("reports every unbound variable", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-v", "unused=value")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("unbound variable in template: 'platform'"))
+			Expect(sess.Err).To(gbytes.Say("unbound variable in template: 'image'"))
+		})
+	})
+
+	Context("when a params file is specified", func() {
+		var paramsPath string
+
+		BeforeEach(func() {
+			paramsPath = filepath.Join(tmpdir, "params.yml")
+
+			err := ioutil.WriteFile(paramsPath, []byte("FOO: from-file\nNEW: 2\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			(*expectedPlan.Do)[1].Task.Config.Params = map[string]string{
+				"FOO": "from-file",
+				"BAZ": "buzz",
+				"X":   "1",
+				"NEW": "2",
+			}
+		})
+
+		It("sets the params, overriding the environment", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--params-file", paramsPath)
+			flyCmd.Dir = buildDir
+			flyCmd.Env = append(os.Environ(), "FOO=from-env")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+	})
+
 	Context("when the build is interrupted", func() {
 		var aborted chan struct{}
 
@@ -538,7 +1096,66 @@ run:
 					Expect(sess.ExitCode()).To(Equal(2))
 				})
 			})
+
+			Describe("while detaching and uploading inputs", func() {
+				It("aborts the build and exits nonzero", func() {
+					uploading := make(chan struct{})
+					abortedDetached := make(chan struct{})
+
+					var once sync.Once
+					atcServer.RouteToHandler("POST", "/api/v1/builds/128/abort",
+						func(w http.ResponseWriter, r *http.Request) {
+							once.Do(func() { close(abortedDetached) })
+						},
+					)
+					atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id",
+						func(w http.ResponseWriter, r *http.Request) {
+							close(uploading)
+							<-abortedDetached
+							w.WriteHeader(http.StatusInternalServerError)
+						},
+					)
+
+					flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--detach")
+					flyCmd.Dir = buildDir
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(uploading, 5).Should(BeClosed())
+
+					sess.Signal(os.Interrupt)
+
+					Eventually(abortedDetached, 5.0).Should(BeClosed())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+
+					Expect(sess.Out).NotTo(gbytes.Say("detached"))
+				})
+			})
 		}
+
+		Describe("by reaching the --timeout", func() {
+			It("aborts the build and exits with the timeout exit code", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--timeout", "1s")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(streaming, 5).Should(BeClosed())
+
+				Eventually(aborted, 5.0).Should(BeClosed())
+				Expect(sess.Err).To(gbytes.Say("timed out after 1s, aborting..."))
+
+				events <- event.Status{Status: atc.StatusAborted}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(124))
+			})
+		})
 	})
 
 	Context("when the target has an auth token", func() {
@@ -600,6 +1217,20 @@ run:
 
 			Expect(uploadingBits).To(BeClosed())
 		})
+
+		It("redacts the auth token when printing the plan", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--dry-run")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("authorization: REDACTED"))
+			Expect(sess.Out.Contents()).NotTo(ContainSubstring("some-token"))
+		})
 	})
 
 	Context("when the build succeeds", func() {
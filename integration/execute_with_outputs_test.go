@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -93,6 +94,9 @@ run:
 						Source: atc.Source{
 							"uri": atcServer.URL() + "/api/v1/pipes/input-pipe-id",
 						},
+						Params: atc.Params{
+							"compression": "gzip",
+						},
 					}),
 				}),
 				planFactory.NewPlan(atc.TaskPlan{
@@ -126,7 +130,8 @@ run:
 						"uri": atcServer.URL() + "/api/v1/pipes/output-pipe-id",
 					},
 					Params: atc.Params{
-						"directory": "some-dir",
+						"compression": "gzip",
+						"directory":   "some-dir",
 					},
 				}),
 			}),
@@ -304,6 +309,138 @@ run:
 			})
 		})
 
+		Context("when running with --detach", func() {
+			It("exits once the inputs are uploaded, and the outputs can be downloaded later", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--detach")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(uploadingBits).To(BeClosed())
+				Expect(streaming).NotTo(BeClosed())
+
+				Expect(sess.Out).To(gbytes.Say("executing build 128"))
+				Expect(sess.Out).To(gbytes.Say("fly -t " + targetName + " watch -b 128"))
+				Expect(sess.Out).To(gbytes.Say("fly -t " + targetName + " download-outputs -b 128"))
+
+				downloadCmd := exec.Command(flyPath, "-t", targetName, "download-outputs", "-b", "128")
+
+				sess, err = gexec.Start(downloadCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).To(gbytes.Say("waiting for build 128's outputs..."))
+
+				data, err := ioutil.ReadFile(filepath.Join(outputDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]byte("tar-contents")))
+
+				downloadCmd = exec.Command(flyPath, "-t", targetName, "download-outputs", "-b", "128")
+
+				sess, err = gexec.Start(downloadCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("no outputs were saved for this build"))
+			})
+
+			Context("when uploading an input fails", func() {
+				var aborted chan struct{}
+
+				JustBeforeEach(func() {
+					aborted = make(chan struct{})
+
+					atcServer.RouteToHandler("PUT", "/api/v1/pipes/input-pipe-id",
+						ghttp.RespondWith(http.StatusInternalServerError, ""),
+					)
+					atcServer.RouteToHandler("POST", "/api/v1/builds/128/abort",
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", "/api/v1/builds/128/abort"),
+							func(w http.ResponseWriter, r *http.Request) {
+								close(aborted)
+							},
+						),
+					)
+				})
+
+				It("aborts the build and exits 1", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--detach")
+					flyCmd.Dir = buildDir
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+
+					Expect(aborted).To(BeClosed())
+
+					Expect(sess.Err).To(gbytes.Say("failed to upload input 'fixture', aborted build 128"))
+					Expect(sess.Out).NotTo(gbytes.Say("detached"))
+				})
+			})
+		})
+
+		Context("when the output path is -", func() {
+			It("writes the output as a tarball to stdout, and the build log to stderr", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir=-")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, nil, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				// sync with after create
+				Eventually(streaming, 5.0).Should(BeClosed())
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).To(gbytes.Say("executing build 128"))
+
+				tr := tar.NewReader(bytes.NewReader(sess.Out.Contents()))
+
+				hdr, err := tr.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hdr.Name).To(Equal("some-file"))
+
+				data, err := ioutil.ReadAll(tr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]byte("tar-contents")))
+			})
+		})
+
+		Context("when the output path names an archive", func() {
+			It("saves the archive without extracting it", func() {
+				archivePath := filepath.Join(outputDir, "nested", "some-dir.tgz")
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+archivePath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				// sync with after create
+				Eventually(streaming, 5.0).Should(BeClosed())
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(tarFiles(archivePath)).To(ContainSubstring("some-file"))
+			})
+		})
+
 		Context("when the task does not specify those outputs", func() {
 			It("exits 1", func() {
 				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-o", "wrong-output=wrong-path")
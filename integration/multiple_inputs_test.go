@@ -86,6 +86,9 @@ run:
 					Source: atc.Source{
 						"uri": atcServer.URL() + "/api/v1/pipes/some-pipe-id",
 					},
+					Params: atc.Params{
+						"compression": "gzip",
+					},
 				}),
 				planFactory.NewPlan(atc.GetPlan{
 					Name: "some-other-input",
@@ -93,6 +96,9 @@ run:
 					Source: atc.Source{
 						"uri": atcServer.URL() + "/api/v1/pipes/some-other-pipe-id",
 					},
+					Params: atc.Params{
+						"compression": "gzip",
+					},
 				}),
 			}),
 			planFactory.NewPlan(atc.TaskPlan{
@@ -16,6 +16,7 @@ import (
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
+	"github.com/tedsuo/rata"
 	"github.com/vito/go-sse/sse"
 
 	"github.com/concourse/atc"
@@ -85,6 +86,9 @@ run:
 					Source: atc.Source{
 						"uri": atcServer.URL() + "/api/v1/pipes/some-pipe-id",
 					},
+					Params: atc.Params{
+						"compression": "gzip",
+					},
 				}),
 				planFactory.NewPlan(atc.GetPlan{
 					Name:    "some-other-input",
@@ -153,6 +157,13 @@ run:
 						Version:  atc.Version{"some": "other-version"},
 						Tags:     atc.Tags{"tag-1", "tag-2"},
 					},
+					{
+						Name:     "some-image",
+						Type:     "docker-image",
+						Resource: "some-image-resource",
+						Source:   atc.Source{"repository": "some/image"},
+						Version:  atc.Version{"digest": "some-digest"},
+					},
 				}),
 			),
 		)
@@ -262,4 +273,268 @@ run:
 		<-sess.Exited
 		Expect(sess).To(gexec.Exit(0))
 	})
+
+	Context("when an input is mapped to a differently named job input", func() {
+		BeforeEach(func() {
+			(*(*expectedPlan.Do)[0].Aggregate)[1].Get = &atc.GetPlan{
+				Name:    "some-other-input",
+				Type:    "git",
+				Source:  atc.Source{"uri": "https://internet.com"},
+				Params:  atc.Params{"some": "params"},
+				Version: atc.Version{"some": "version"},
+				Tags:    atc.Tags{"tag-1", "tag-2"},
+			}
+		})
+
+		It("takes the input from the mapped job input", func() {
+			flyCmd := exec.Command(
+				flyPath, "-t", targetName, "e",
+				"--inputs-from", "some-pipeline/some-job",
+				"--input", fmt.Sprintf("some-input=%s", buildDir),
+				"--input-mapping", "some-other-input=some-input",
+				"--config", filepath.Join(buildDir, "task.yml"),
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess).To(gexec.Exit(0))
+		})
+
+		Context("when the task has no such input", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"--inputs-from", "some-pipeline/some-job",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+					"--input-mapping", "bogus-input=some-input",
+					"--config", filepath.Join(buildDir, "task.yml"),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("unknown input `bogus-input`"))
+
+				<-sess.Exited
+				Expect(sess).To(gexec.Exit(1))
+			})
+		})
+	})
+
+	Context("when running a task of the job", func() {
+		BeforeEach(func() {
+			(*(*expectedPlan.Do)[0].Aggregate)[1].Get = &atc.GetPlan{
+				Name:    "some-other-input",
+				Type:    "git",
+				Source:  atc.Source{"uri": "https://internet.com"},
+				Params:  atc.Params{"some": "params"},
+				Version: atc.Version{"some": "version"},
+				Tags:    atc.Tags{"tag-1", "tag-2"},
+			}
+
+			(*expectedPlan.Do)[1].Task.Privileged = true
+			(*expectedPlan.Do)[1].Task.Config.Params["FOO"] = "from-step"
+
+			config := atc.Config{
+				Jobs: atc.JobConfigs{
+					{
+						Name: "some-job",
+						Plan: atc.PlanSequence{
+							{Get: "some-input"},
+							{
+								Aggregate: &atc.PlanSequence{
+									{
+										Task:           "unit",
+										TaskConfigPath: "some-input/task.yml",
+										Privileged:     true,
+										Params:         atc.Params{"FOO": "from-step"},
+										InputMapping:   map[string]string{"some-other-input": "some-input"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			path, err := atc.Routes.CreatePathForRoute(atc.GetConfig, rata.Params{"pipeline_name": "some-pipeline"})
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.RouteToHandler("GET", path,
+				ghttp.RespondWithJSONEncoded(200, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+			)
+		})
+
+		It("runs the task with the job's config, params and input mappings", func() {
+			flyCmd := exec.Command(
+				flyPath, "-t", targetName, "e",
+				"--inputs-from", "some-pipeline/some-job",
+				"--task", "unit",
+				"--input", fmt.Sprintf("some-input=%s", buildDir),
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess).To(gexec.Exit(0))
+		})
+
+		Context("when the artifact holding the task config is not provided locally", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"--inputs-from", "some-pipeline/some-job",
+					"--task", "unit",
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("task config `some-input/task.yml` comes from input `some-input`"))
+
+				<-sess.Exited
+				Expect(sess).To(gexec.Exit(1))
+			})
+		})
+
+		Context("when the task config comes from an artifact that is not an input of the task", func() {
+			BeforeEach(func() {
+				config := atc.Config{
+					Jobs: atc.JobConfigs{
+						{
+							Name: "some-job",
+							Plan: atc.PlanSequence{
+								{Get: "ci"},
+								{Get: "some-input"},
+								{
+									Task:           "unit",
+									TaskConfigPath: "ci/task.yml",
+									Privileged:     true,
+									Params:         atc.Params{"FOO": "from-step"},
+									InputMapping:   map[string]string{"some-other-input": "some-input"},
+								},
+							},
+						},
+					},
+				}
+
+				path, err := atc.Routes.CreatePathForRoute(atc.GetConfig, rata.Params{"pipeline_name": "some-pipeline"})
+				Expect(err).NotTo(HaveOccurred())
+
+				atcServer.RouteToHandler("GET", path,
+					ghttp.RespondWithJSONEncoded(200, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				)
+			})
+
+			It("reads the config from the artifact given with -i, without uploading it", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"--inputs-from", "some-pipeline/some-job",
+					"--task", "unit",
+					"--input", fmt.Sprintf("ci=%s", buildDir),
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+				Eventually(uploading).Should(BeClosed())
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess).To(gexec.Exit(0))
+			})
+		})
+
+		Context("when the job has no such task", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"--inputs-from", "some-pipeline/some-job",
+					"--task", "bogus",
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("job 'some-pipeline/some-job' has no task `bogus`"))
+
+				<-sess.Exited
+				Expect(sess).To(gexec.Exit(1))
+			})
+		})
+	})
+
+	Context("when the image comes from a job", func() {
+		BeforeEach(func() {
+			planFactory := atc.NewPlanFactory(0)
+
+			aggregate := (*expectedPlan.Do)[0].Aggregate
+			*aggregate = append(*aggregate, planFactory.NewPlan(atc.GetPlan{
+				Name:    "some-image",
+				Type:    "docker-image",
+				Source:  atc.Source{"repository": "some/image"},
+				Version: atc.Version{"digest": "some-digest"},
+			}))
+
+			(*expectedPlan.Do)[1].Task.ImageArtifactName = "some-image"
+			(*expectedPlan.Do)[1].Task.Config.Image = ""
+		})
+
+		It("fetches the image resource and runs the task with it", func() {
+			flyCmd := exec.Command(
+				flyPath, "-t", targetName, "e",
+				"--inputs-from", "some-pipeline/some-job",
+				"--input", fmt.Sprintf("some-input=%s", buildDir),
+				"--image-from", "some-pipeline/some-job:some-image-resource",
+				"--config", filepath.Join(buildDir, "task.yml"),
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess).To(gexec.Exit(0))
+		})
+
+		Context("when the job has no such resource", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"--inputs-from", "some-pipeline/some-job",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+					"--image-from", "some-pipeline/some-job:bogus-resource",
+					"--config", filepath.Join(buildDir, "task.yml"),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("job 'some-pipeline/some-job' has no input for resource `bogus-resource`"))
+
+				<-sess.Exited
+				Expect(sess).To(gexec.Exit(1))
+			})
+		})
+	})
 })
@@ -0,0 +1,140 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("RerunBuild", func() {
+	Context("with a build of a job", func() {
+		BeforeEach(func() {
+			config := atc.Config{
+				Resources: atc.ResourceConfigs{
+					{
+						Name:   "some-resource",
+						Type:   "git",
+						Source: atc.Source{"uri": "https://example.com"},
+					},
+					{
+						Name:   "some-output",
+						Type:   "s3",
+						Source: atc.Source{"bucket": "some-bucket"},
+					},
+				},
+				Jobs: atc.JobConfigs{
+					{
+						Name: "some-job",
+						Plan: atc.PlanSequence{
+							{Get: "some-input", Resource: "some-resource"},
+							{Task: "some-task", TaskConfigPath: "some-input/task.yml"},
+							{Put: "some-output"},
+						},
+					},
+				},
+			}
+
+			fact := atc.NewPlanFactory(0)
+
+			expectedPlan := fact.NewPlan(atc.DoPlan{
+				fact.NewPlan(atc.GetPlan{
+					Name:     "some-input",
+					Resource: "some-resource",
+					Type:     "git",
+					Source:   atc.Source{"uri": "https://example.com"},
+					Version:  atc.Version{"ref": "abc123"},
+				}),
+				fact.NewPlan(atc.TaskPlan{
+					Name:       "some-task",
+					ConfigPath: "some-input/task.yml",
+				}),
+			})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines/some-pipeline/jobs/some-job/builds/3"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{
+						ID:           23,
+						Name:         "3",
+						Status:       "failed",
+						PipelineName: "some-pipeline",
+						JobName:      "some-job",
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/23/resources"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.BuildInputsOutputs{
+						Inputs: []atc.PublicBuildInput{
+							{Name: "some-input", Resource: "some-resource", Version: atc.Version{"ref": "abc123"}},
+						},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/builds"),
+					VerifyPlan(expectedPlan),
+					ghttp.RespondWith(http.StatusCreated, `{"id":128}`),
+				),
+			)
+		})
+
+		It("creates a build of the job's steps with the versions the build fetched, leaving out its puts", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "rerun-build", "-j", "some-pipeline/some-job", "-b", "3")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say("started build 128, rerunning 'some-pipeline/some-job' #3"))
+
+			Expect(sess.Err).To(gbytes.Say("warning: rerunning with the current config of 'some-pipeline/some-job', not the one build #3 ran with"))
+			Expect(sess.Err).To(gbytes.Say("leaving out put steps: some-output; run with --with-puts to include them"))
+		})
+
+		It("does not keep the plan, which has the resources' sources in it", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "rerun-build", "-j", "some-pipeline/some-job", "-b", "3")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(filepath.Join(homeDir, ".fly", "plans", targetName, "128.json")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("with a one-off build that was not executed from this machine", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/42"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 42, Name: "42", Status: "failed"}),
+				),
+			)
+		})
+
+		It("errors", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "rerun-build", "-b", "42")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			Expect(sess.Err).To(gbytes.Say("no plan was saved for this build"))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package integration_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"os/exec"
 
@@ -8,10 +10,12 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/tedsuo/rata"
+	"github.com/vito/go-sse/sse"
 )
 
 var _ = Describe("Fly CLI", func() {
@@ -53,6 +57,68 @@ var _ = Describe("Fly CLI", func() {
 				})
 			})
 
+			Context("with --watch or --wait", func() {
+				BeforeEach(func() {
+					atcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", path),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 123, Name: "57", Status: "pending"}),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/api/v1/builds/123/events"),
+							func(w http.ResponseWriter, r *http.Request) {
+								w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+								w.WriteHeader(http.StatusOK)
+
+								events := []atc.Event{
+									event.Status{Status: atc.StatusStarted},
+									event.Log{Payload: "building\n"},
+									event.FinishTask{ExitStatus: 4},
+									event.Status{Status: atc.StatusFailed},
+								}
+
+								for id, e := range events {
+									payload, err := json.Marshal(event.Message{Event: e})
+									Expect(err).NotTo(HaveOccurred())
+
+									err = sse.Event{ID: fmt.Sprintf("%d", id), Name: "event", Data: payload}.Write(w)
+									Expect(err).NotTo(HaveOccurred())
+								}
+
+								err := sse.Event{Name: "end"}.Write(w)
+								Expect(err).NotTo(HaveOccurred())
+							},
+						),
+					)
+				})
+
+				It("streams the build with --watch and exits with its status", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "trigger-job", "-j", "awesome-pipeline/awesome-job", "--watch")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(4))
+
+					Expect(sess.Out).To(gbytes.Say(`started 'awesome-pipeline/awesome-job' #57`))
+					Expect(sess.Out).To(gbytes.Say(`building`))
+					Expect(sess.Out).To(gbytes.Say(`failed`))
+				})
+
+				It("prints only the final status with --wait and exits with the same status", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "trigger-job", "-j", "awesome-pipeline/awesome-job", "--wait")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(4))
+
+					Expect(string(sess.Out.Contents())).To(Equal("failed\n"))
+				})
+			})
+
 			Context("when the pipeline/job doesn't exist", func() {
 				BeforeEach(func() {
 					atcServer.AppendHandlers(
@@ -0,0 +1,144 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("validate-task", func() {
+		var (
+			tmpdir         string
+			taskConfigPath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-validate-task")
+			Expect(err).NotTo(HaveOccurred())
+
+			taskConfigPath = filepath.Join(tmpdir, "task.yml")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		writeConfig := func(config string) {
+			err := ioutil.WriteFile(taskConfigPath, []byte(config), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Context("when the task config is valid", func() {
+			BeforeEach(func() {
+				writeConfig(`---
+platform: linux
+
+inputs:
+- name: some-input
+
+run:
+  path: ls
+`)
+			})
+
+			It("succeeds without a target", func() {
+				flyCmd := exec.Command(flyPath, "validate-task", "-c", taskConfigPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("looks good"))
+				Expect(atcServer.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("when an unknown input is given", func() {
+				It("prints an error and exits 1", func() {
+					flyCmd := exec.Command(flyPath, "validate-task", "-c", taskConfigPath, "-i", "bogus-input="+tmpdir)
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+
+					Expect(sess.Err).To(gbytes.Say("task.yml: error: unknown input `bogus-input`"))
+				})
+			})
+		})
+
+		Context("when the task config has unknown keys", func() {
+			BeforeEach(func() {
+				writeConfig(`---
+platform: linux
+
+run:
+  path: ls
+  argz: [-al]
+`)
+			})
+
+			It("warns about them, and succeeds", func() {
+				flyCmd := exec.Command(flyPath, "validate-task", "-c", taskConfigPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).To(gbytes.Say(`task.yml: warning: unknown key 'run.argz'`))
+			})
+
+			Context("with --strict", func() {
+				It("fails", func() {
+					flyCmd := exec.Command(flyPath, "validate-task", "-c", taskConfigPath, "--strict")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+
+					Expect(sess.Err).To(gbytes.Say("task config is invalid"))
+				})
+			})
+		})
+
+		Context("when the task config is invalid", func() {
+			BeforeEach(func() {
+				writeConfig(`---
+inputs:
+- name: some-input
+
+run:
+  path: ls
+`)
+			})
+
+			It("prints the error and exits 1", func() {
+				flyCmd := exec.Command(flyPath, "validate-task", "-c", taskConfigPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say(`task.yml: error: invalid task configuration`))
+				Expect(sess.Err).To(gbytes.Say(`missing 'platform'`))
+				Expect(sess.Err).To(gbytes.Say("task config is invalid"))
+			})
+		})
+	})
+})
@@ -3,8 +3,12 @@ package integration_test
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,6 +19,7 @@ import (
 
 	"github.com/concourse/atc"
 	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
 )
 
 var _ = Describe("Watching", func() {
@@ -111,6 +116,494 @@ var _ = Describe("Watching", func() {
 		})
 	})
 
+	Context("with --output json", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		It("prints each event as a JSON object and exits with the build's status", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--output", "json")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Log{
+				Payload: "sup",
+				Origin: event.Origin{
+					Name: "some-task",
+					Type: event.OriginTypeTask,
+				},
+			}
+
+			events <- event.FinishTask{ExitStatus: 3}
+			events <- event.Status{Status: atc.StatusFailed, Time: 1234}
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(3))
+
+			lines := strings.Split(strings.TrimSpace(string(sess.Out.Contents())), "\n")
+			Expect(lines).To(HaveLen(3))
+
+			var log map[string]interface{}
+			err = json.Unmarshal([]byte(lines[0]), &log)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(log["type"]).To(Equal("log"))
+			Expect(log["origin"]).To(HaveKeyWithValue("name", "some-task"))
+			Expect(log["payload"]).To(HaveKeyWithValue("payload", "sup"))
+
+			var status map[string]interface{}
+			err = json.Unmarshal([]byte(lines[2]), &status)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(status["type"]).To(Equal("status"))
+			Expect(status["timestamp"]).To(Equal(1234.0))
+		})
+	})
+
+	Context("with --grep", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		It("prints only the matching lines and their context, and exits with the build's status", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--grep", "ERR", "-C", "1")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Log{Payload: "one\ntwo\nERR three\nfour\nfive\nsix\n"}
+			events <- event.FinishTask{ExitStatus: 3}
+			events <- event.Status{Status: atc.StatusFailed}
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(3))
+
+			Expect(string(sess.Out.Contents())).To(Equal("two\nERR three\nfour\nfailed\n"))
+		})
+	})
+
+	Context("with --log-file", func() {
+		var tmpdir string
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-log-file")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		watchWithLogFile := func(args ...string) *gexec.Session {
+			watchArgs := append([]string{"-t", targetName, "watch", "-b", "3", "--log-file", filepath.Join(tmpdir, "build.log")}, args...)
+
+			sess, err := gexec.Start(exec.Command(flyPath, watchArgs...), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.InitializeTask{TaskConfig: event.TaskConfig{Image: "some-image"}}
+			events <- event.Log{Payload: "sup\n"}
+			events <- event.Status{Status: atc.StatusSucceeded}
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			return sess
+		}
+
+		It("copies the output to the file without escape sequences", func() {
+			sess := watchWithLogFile()
+
+			Expect(sess.Out.Contents()).To(ContainSubstring("\x1b[1minitializing with some-image\x1b[0m\n"))
+
+			contents, err := ioutil.ReadFile(filepath.Join(tmpdir, "build.log"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(contents)).To(Equal("initializing with some-image\nsup\nsucceeded\n"))
+		})
+
+		Context("with --log-file-keep-ansi", func() {
+			It("copies the output to the file as it is", func() {
+				sess := watchWithLogFile("--log-file-keep-ansi")
+
+				contents, err := ioutil.ReadFile(filepath.Join(tmpdir, "build.log"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(contents).To(Equal(sess.Out.Contents()))
+			})
+		})
+	})
+
+	Context("with an invalid --grep pattern", func() {
+		It("errors", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--grep", "ERR(")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say(`invalid regular expression 'ERR\('`))
+		})
+	})
+
+	Context("with a job's name alone, without --pipeline", func() {
+		It("errors", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-j", "some-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("job 'some-job' must be given as PIPELINE/JOB, or with --pipeline"))
+		})
+	})
+
+	Context("when the connection to the event stream drops", func() {
+		BeforeEach(func() {
+			writeEvent := func(w http.ResponseWriter, id int, e atc.Event) {
+				payload, err := json.Marshal(event.Message{Event: e})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{
+					ID:   fmt.Sprintf("%d", id),
+					Name: "event",
+					Data: payload,
+				}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.(http.Flusher).Flush()
+			}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						writeEvent(w, 0, event.Log{Payload: "before the drop\n"})
+
+						conn, _, err := w.(http.Hijacker).Hijack()
+						Expect(err).NotTo(HaveOccurred())
+
+						conn.Close()
+					},
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+					ghttp.VerifyHeaderKV("Last-Event-ID", "0"),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						writeEvent(w, 1, event.Log{Payload: "after the drop\n"})
+						writeEvent(w, 2, event.Status{Status: atc.StatusSucceeded})
+
+						err := sse.Event{Name: "end"}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					},
+				),
+			)
+		})
+
+		It("reconnects and resumes after the last event it saw", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Exited, 5).Should(BeClosed())
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(string(sess.Out.Contents())).To(Equal("before the drop\nafter the drop\n" + ui.SucceededColor.SprintFunc()("succeeded") + "\n"))
+		})
+
+		Context("when it cannot reconnect before --reconnect-timeout", func() {
+			It("gives up", func() {
+				atcServer.SetHandler(2, ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+					ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+				))
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--reconnect-timeout", "0s")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(255))
+				Expect(sess.Out).To(gbytes.Say("failed to parse next event"))
+			})
+		})
+	})
+
+	Context("with --record", func() {
+		var recordingDir string
+
+		BeforeEach(func() {
+			var err error
+			recordingDir, err = ioutil.TempDir("", "fly-recording")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(recordingDir)
+		})
+
+		It("saves the events so that 'replay' can play them back", func() {
+			recordingPath := filepath.Join(recordingDir, "build.events")
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--record", recordingPath)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Log{Payload: "sup\n"}
+			events <- event.FinishTask{ExitStatus: 3}
+			events <- event.Status{Status: atc.StatusFailed}
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(3))
+
+			replayCmd := exec.Command(flyPath, "replay", recordingPath, "--speed", "0")
+
+			replaySess, err := gexec.Start(replayCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-replaySess.Exited
+			Expect(replaySess.ExitCode()).To(Equal(3))
+
+			Expect(replaySess.Out.Contents()).To(Equal(sess.Out.Contents()))
+		})
+	})
+
+	Context("with --pipeline", func() {
+		buildEventsHandler := func(events ...atc.Event) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				for id, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{
+						ID:   fmt.Sprintf("%d", id),
+						Name: "event",
+						Data: payload,
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+
+		BeforeEach(func() {
+			atcServer.RouteToHandler("GET", "/api/v1/pipelines/some-pipeline/jobs",
+				ghttp.RespondWithJSONEncoded(200, []atc.Job{
+					{
+						Name:      "job-a",
+						NextBuild: &atc.Build{ID: 10, Name: "7", Status: "started", JobName: "job-a"},
+					},
+					{
+						Name:      "job-b",
+						NextBuild: &atc.Build{ID: 11, Name: "3", Status: "started", JobName: "job-b"},
+					},
+					{
+						Name:          "job-c",
+						FinishedBuild: &atc.Build{ID: 9, Name: "1", Status: "succeeded", JobName: "job-c"},
+					},
+				}),
+			)
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/10/events", buildEventsHandler(
+				event.Log{Payload: "from a\n"},
+				event.Status{Status: atc.StatusSucceeded},
+			))
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/11/events", buildEventsHandler(
+				event.Log{Payload: "from b\n"},
+				event.FinishTask{ExitStatus: 5},
+				event.Status{Status: atc.StatusFailed},
+			))
+		})
+
+		It("watches every running build, prefixing their lines, and exits with the highest exit code", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-p", "some-pipeline")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Exited, 10).Should(BeClosed())
+			Expect(sess.ExitCode()).To(Equal(5))
+
+			Expect(sess.Out).To(gbytes.Say(`job-a#7.* from a`))
+			Expect(sess.Out.Contents()).To(MatchRegexp(`job-b#3.* from b`))
+			Expect(sess.Out.Contents()).To(MatchRegexp(`job-b#3.* .*failed`))
+		})
+
+		Context("with --job", func() {
+			It("only watches builds of those jobs", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-p", "some-pipeline", "-j", "job-a")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Exited, 10).Should(BeClosed())
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out.Contents()).To(ContainSubstring("from a"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("job-b"))
+			})
+
+			It("watches builds of each job given", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-p", "some-pipeline", "-j", "job-a", "-j", "job-b")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Exited, 10).Should(BeClosed())
+				Expect(sess.ExitCode()).To(Equal(5))
+
+				Expect(sess.Out.Contents()).To(ContainSubstring("from a"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("from b"))
+			})
+
+			Context("when the job does not exist", func() {
+				It("errors", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-p", "some-pipeline", "-j", "bogus")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.Err).To(gbytes.Say("job 'bogus' not found in pipeline 'some-pipeline'"))
+				})
+			})
+		})
+	})
+
+	Context("with --follow", func() {
+		finishedEventsHandler := func(buildID int, payload string, status atc.BuildStatus) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID)),
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+
+					for id, e := range []atc.Event{event.Log{Payload: payload}, event.Status{Status: status}} {
+						data, err := json.Marshal(event.Message{Event: e})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{ID: fmt.Sprintf("%d", id), Name: "event", Data: data}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					err := sse.Event{Name: "end"}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				},
+			)
+		}
+
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job"),
+					ghttp.RespondWithJSONEncoded(200, atc.Job{
+						FinishedBuild: &atc.Build{ID: 3, Name: "3", Status: "succeeded", JobName: "some-job"},
+					}),
+				),
+				finishedEventsHandler(3, "first build\n", atc.StatusSucceeded),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job"),
+					ghttp.RespondWithJSONEncoded(200, atc.Job{
+						FinishedBuild: &atc.Build{ID: 3, Name: "3", Status: "succeeded", JobName: "some-job"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job"),
+					ghttp.RespondWithJSONEncoded(200, atc.Job{
+						NextBuild:     &atc.Build{ID: 4, Name: "4", Status: "started", JobName: "some-job"},
+						FinishedBuild: &atc.Build{ID: 3, Name: "3", Status: "succeeded", JobName: "some-job"},
+					}),
+				),
+				finishedEventsHandler(4, "second build\n", atc.StatusFailed),
+			)
+		})
+
+		It("attaches to each build of the job as it starts, until --count builds have been watched", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-j", "main/some-job", "--follow", "--count", "2")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Exited, 10).Should(BeClosed())
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Out).To(gbytes.Say("==== some-job #3 ===="))
+			Expect(sess.Out).To(gbytes.Say("first build"))
+			Expect(sess.Out).To(gbytes.Say("==== some-job #4 ===="))
+			Expect(sess.Out).To(gbytes.Say("second build"))
+			Expect(sess.Out).To(gbytes.Say("failed"))
+		})
+	})
+
 	Context("with a specific job and pipeline", func() {
 		Context("when the job has no builds", func() {
 			BeforeEach(func() {
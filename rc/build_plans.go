@@ -0,0 +1,99 @@
+package rc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+)
+
+var ErrNoBuildPlan = errors.New("no plan was saved for this build; one-off builds can only be rerun if they were executed from this machine without uploading inputs or downloading outputs")
+
+// only the plans of this many of the most recent one-off builds are kept
+const maxSavedBuildPlans = 100
+
+// SaveBuildPlan keeps the plan of a one-off build so that it can be rerun.
+// The API only shows a build's plan with the sources and params of its steps
+// left out.
+func SaveBuildPlan(targetName TargetName, buildID int, plan atc.Plan) error {
+	location := buildPlanLocation(targetName, buildID)
+
+	err := os.MkdirAll(filepath.Dir(location), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %s", filepath.Dir(location), err)
+	}
+
+	jsonBytes, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("could not marshal %s", location)
+	}
+
+	// the sources of the plan's steps may have credentials in them
+	err = ioutil.WriteFile(location, jsonBytes, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s", location)
+	}
+
+	pruneBuildFiles(filepath.Dir(location), ".json", maxSavedBuildPlans)
+
+	return nil
+}
+
+func LoadBuildPlan(targetName TargetName, buildID int) (atc.Plan, error) {
+	location := buildPlanLocation(targetName, buildID)
+
+	jsonBytes, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) {
+		return atc.Plan{}, ErrNoBuildPlan
+	}
+
+	if err != nil {
+		return atc.Plan{}, fmt.Errorf("could not read %s", location)
+	}
+
+	var plan atc.Plan
+	err = json.Unmarshal(jsonBytes, &plan)
+	if err != nil {
+		return atc.Plan{}, fmt.Errorf("could not unmarshal %s", location)
+	}
+
+	return plan, nil
+}
+
+// pruneBuildFiles removes all but the keep most recent of the files in dir
+// that are named after the IDs of builds
+func pruneBuildFiles(dir string, ext string, keep int) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	buildIDs := []int{}
+	for _, info := range infos {
+		buildID, err := strconv.Atoi(strings.TrimSuffix(info.Name(), ext))
+		if err == nil {
+			buildIDs = append(buildIDs, buildID)
+		}
+	}
+
+	if len(buildIDs) <= keep {
+		return
+	}
+
+	sort.Ints(buildIDs)
+
+	for _, buildID := range buildIDs[:len(buildIDs)-keep] {
+		os.Remove(filepath.Join(dir, strconv.Itoa(buildID)+ext))
+	}
+}
+
+func buildPlanLocation(targetName TargetName, buildID int) string {
+	return filepath.Join(userHomeDir(), ".fly", "plans", string(targetName), strconv.Itoa(buildID)+".json")
+}
@@ -0,0 +1,73 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build plans", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		if runtime.GOOS == "windows" {
+			os.Setenv("USERPROFILE", tmpDir)
+		} else {
+			os.Setenv("HOME", tmpDir)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	plan := atc.Plan{
+		ID: "some-id",
+		Get: &atc.GetPlan{
+			Name:    "some-input",
+			Type:    "git",
+			Source:  atc.Source{"uri": "https://example.com"},
+			Version: atc.Version{"ref": "abc123"},
+		},
+	}
+
+	It("loads the plan that was saved for a build of a target", func() {
+		err := rc.SaveBuildPlan("some-target", 42, plan)
+		Expect(err).ToNot(HaveOccurred())
+
+		loaded, err := rc.LoadBuildPlan("some-target", 42)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(plan))
+
+		_, err = rc.LoadBuildPlan("other-target", 42)
+		Expect(err).To(Equal(rc.ErrNoBuildPlan))
+
+		_, err = rc.LoadBuildPlan("some-target", 43)
+		Expect(err).To(Equal(rc.ErrNoBuildPlan))
+	})
+
+	It("only keeps the plans of the most recent builds", func() {
+		for buildID := 1; buildID <= 101; buildID++ {
+			err := rc.SaveBuildPlan("some-target", buildID, plan)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		_, err := rc.LoadBuildPlan("some-target", 1)
+		Expect(err).To(Equal(rc.ErrNoBuildPlan))
+
+		_, err = rc.LoadBuildPlan("some-target", 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = rc.LoadBuildPlan("some-target", 101)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,85 @@
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+var ErrNoDetachedOutputs = errors.New("no outputs were saved for this build; was it executed with --detach and --output?")
+
+// only the outputs of this many of the most recent detached builds are kept,
+// in case they are never downloaded
+const maxSavedDetachedOutputs = 100
+
+// DetachedOutput is an output of a one-off build that fly detached from,
+// along with the pipe it will be streamed through when the build finishes.
+type DetachedOutput struct {
+	Name        string `yaml:"name"`
+	Path        string `yaml:"path"`
+	ReadURL     string `yaml:"read_url"`
+	Compression string `yaml:"compression"`
+}
+
+func SaveDetachedOutputs(targetName TargetName, buildID int, outputs []DetachedOutput) error {
+	location := detachedOutputsLocation(targetName, buildID)
+
+	err := os.MkdirAll(filepath.Dir(location), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %s", filepath.Dir(location), err)
+	}
+
+	yamlBytes, err := yaml.Marshal(outputs)
+	if err != nil {
+		return fmt.Errorf("could not marshal %s", location)
+	}
+
+	// the pipes' URLs are as good as credentials for the outputs
+	err = ioutil.WriteFile(location, yamlBytes, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s", location)
+	}
+
+	pruneBuildFiles(filepath.Dir(location), ".yml", maxSavedDetachedOutputs)
+
+	return nil
+}
+
+func LoadDetachedOutputs(targetName TargetName, buildID int) ([]DetachedOutput, error) {
+	location := detachedOutputsLocation(targetName, buildID)
+
+	yamlBytes, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) {
+		return nil, ErrNoDetachedOutputs
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s", location)
+	}
+
+	var outputs []DetachedOutput
+	err = yaml.Unmarshal(yamlBytes, &outputs)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal %s", location)
+	}
+
+	return outputs, nil
+}
+
+func RemoveDetachedOutputs(targetName TargetName, buildID int) error {
+	err := os.Remove(detachedOutputsLocation(targetName, buildID))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func detachedOutputsLocation(targetName TargetName, buildID int) string {
+	return filepath.Join(userHomeDir(), ".fly", "detached", string(targetName), strconv.Itoa(buildID)+".yml")
+}
@@ -0,0 +1,85 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detached builds", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		if runtime.GOOS == "windows" {
+			os.Setenv("USERPROFILE", tmpDir)
+		} else {
+			os.Setenv("HOME", tmpDir)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	outputs := []rc.DetachedOutput{
+		{
+			Name:        "some-output",
+			Path:        "/some/path",
+			ReadURL:     "https://example.com/api/v1/pipes/some-pipe",
+			Compression: "gzip:9",
+		},
+	}
+
+	It("loads the outputs that were saved for a build of a target", func() {
+		err := rc.SaveDetachedOutputs("some-target", 42, outputs)
+		Expect(err).ToNot(HaveOccurred())
+
+		loaded, err := rc.LoadDetachedOutputs("some-target", 42)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(outputs))
+
+		_, err = rc.LoadDetachedOutputs("other-target", 42)
+		Expect(err).To(Equal(rc.ErrNoDetachedOutputs))
+
+		_, err = rc.LoadDetachedOutputs("some-target", 43)
+		Expect(err).To(Equal(rc.ErrNoDetachedOutputs))
+	})
+
+	It("only keeps the outputs of the most recent builds", func() {
+		for buildID := 1; buildID <= 101; buildID++ {
+			err := rc.SaveDetachedOutputs("some-target", buildID, outputs)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		_, err := rc.LoadDetachedOutputs("some-target", 1)
+		Expect(err).To(Equal(rc.ErrNoDetachedOutputs))
+
+		_, err = rc.LoadDetachedOutputs("some-target", 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = rc.LoadDetachedOutputs("some-target", 101)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("forgets the outputs once removed", func() {
+		err := rc.SaveDetachedOutputs("some-target", 42, outputs)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = rc.RemoveDetachedOutputs("some-target", 42)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = rc.LoadDetachedOutputs("some-target", 42)
+		Expect(err).To(Equal(rc.ErrNoDetachedOutputs))
+
+		err = rc.RemoveDetachedOutputs("some-target", 42)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
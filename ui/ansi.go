@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// control sequences, such as colors, and operating system commands, such as
+// setting the terminal's title
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)")
+
+// anything longer than this after an escape is not waiting on the rest of
+// a sequence, and is written as it is
+const maxPartialEscape = 32
+
+// StripANSI removes terminal escape sequences from the text.
+func StripANSI(text string) string {
+	return ansiEscape.ReplaceAllString(text, "")
+}
+
+// ANSIStripper writes to dst with escape sequences removed, holding back
+// one that is split across writes until the rest of it arrives.
+type ANSIStripper struct {
+	dst io.Writer
+
+	lock    sync.Mutex
+	partial string
+}
+
+func NewANSIStripper(dst io.Writer) *ANSIStripper {
+	return &ANSIStripper{
+		dst: dst,
+	}
+}
+
+func (stripper *ANSIStripper) Write(p []byte) (int, error) {
+	stripper.lock.Lock()
+	defer stripper.lock.Unlock()
+
+	text := stripper.partial + string(p)
+	stripper.partial = ""
+
+	if i := strings.LastIndexByte(text, '\x1b'); i != -1 && !ansiEscape.MatchString(text[i:]) && len(text)-i <= maxPartialEscape {
+		stripper.partial = text[i:]
+		text = text[:i]
+	}
+
+	_, err := io.WriteString(stripper.dst, StripANSI(text))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close writes out whatever is held back, as the rest of it is not coming,
+// and closes dst if it can be closed.
+func (stripper *ANSIStripper) Close() error {
+	stripper.lock.Lock()
+	defer stripper.lock.Unlock()
+
+	if stripper.partial != "" {
+		_, err := io.WriteString(stripper.dst, StripANSI(stripper.partial))
+		stripper.partial = ""
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if closer, ok := stripper.dst.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
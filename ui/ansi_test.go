@@ -0,0 +1,67 @@
+package ui_test
+
+import (
+	"bytes"
+
+	"github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StripANSI", func() {
+	It("removes colors and other escape sequences", func() {
+		text := "\x1b[1mbold\x1b[0m " + ui.ErroredColor.Sprint("errored") + " \x1b]0;title\x07\x1b[2Kplain"
+
+		Expect(ui.StripANSI(text)).To(Equal("bold errored plain"))
+	})
+
+	It("leaves text without escape sequences alone", func() {
+		Expect(ui.StripANSI("[1] just some text\n")).To(Equal("[1] just some text\n"))
+	})
+})
+
+var _ = Describe("ANSIStripper", func() {
+	var (
+		dst      *bytes.Buffer
+		stripper *ui.ANSIStripper
+	)
+
+	BeforeEach(func() {
+		dst = new(bytes.Buffer)
+		stripper = ui.NewANSIStripper(dst)
+	})
+
+	It("writes what is written to it without escape sequences", func() {
+		n, err := stripper.Write([]byte("\x1b[1minitializing\x1b[0m\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(len("\x1b[1minitializing\x1b[0m\n")))
+
+		Expect(dst.String()).To(Equal("initializing\n"))
+	})
+
+	It("strips escape sequences that are split across writes", func() {
+		_, err := stripper.Write([]byte("one \x1b[3"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dst.String()).To(Equal("one "))
+
+		_, err = stripper.Write([]byte("1mtwo\x1b[0m\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dst.String()).To(Equal("one two\n"))
+	})
+
+	Describe("Close", func() {
+		It("writes out what was held back", func() {
+			_, err := stripper.Write([]byte("one \x1btwo"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dst.String()).To(Equal("one "))
+
+			Expect(stripper.Close()).To(Succeed())
+
+			Expect(dst.String()).To(Equal("one \x1btwo"))
+		})
+	})
+})
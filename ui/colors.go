@@ -1,6 +1,10 @@
 package ui
 
-import "github.com/fatih/color"
+import (
+	"hash/fnv"
+
+	"github.com/fatih/color"
+)
 
 var PendingColor = color.New(color.FgWhite)
 var StartedColor = color.New(color.FgYellow)
@@ -10,3 +14,45 @@ var ErroredColor = color.New(color.FgWhite, color.BgRed, color.Bold)
 var BlinkingErrorColor = color.New(color.BlinkSlow, color.FgWhite, color.BgRed, color.Bold)
 var AbortedColor = color.New(color.FgMagenta)
 var PausedColor = color.New(color.FgCyan)
+
+// StatusColor returns the color to show a build status in, or nil for an
+// unknown status.
+func StatusColor(status string) *color.Color {
+	switch status {
+	case "pending":
+		return PendingColor
+	case "started":
+		return StartedColor
+	case "succeeded":
+		return SucceededColor
+	case "failed":
+		return FailedColor
+	case "errored":
+		return ErroredColor
+	case "aborted":
+		return AbortedColor
+	case "paused":
+		return PausedColor
+	default:
+		return nil
+	}
+}
+
+var nameColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgBlue),
+	color.New(color.FgGreen),
+	color.New(color.FgRed),
+}
+
+// NameColor picks a color for a name, such as a job's, so that output from
+// several sources can be told apart. The same name always gets the same
+// color.
+func NameColor(name string) *color.Color {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+
+	return nameColors[hash.Sum32()%uint32(len(nameColors))]
+}
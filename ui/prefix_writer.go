@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+)
+
+// PrefixWriter writes each line written to it to dst with the prefix in
+// front. Lines are only written once complete, each in a single write, so
+// that several PrefixWriters can share a destination without interleaving
+// within lines.
+type PrefixWriter struct {
+	dst     io.Writer
+	prefix  []byte
+	partial []byte
+}
+
+func NewPrefixWriter(dst io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		dst:    dst,
+		prefix: []byte(prefix),
+	}
+}
+
+func (writer *PrefixWriter) Write(p []byte) (int, error) {
+	writer.partial = append(writer.partial, p...)
+
+	for {
+		i := bytes.IndexByte(writer.partial, '\n')
+		if i == -1 {
+			break
+		}
+
+		err := writer.writeLine(writer.partial[:i+1])
+		if err != nil {
+			return 0, err
+		}
+
+		writer.partial = writer.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out the final line, if it was not terminated.
+func (writer *PrefixWriter) Flush() error {
+	if len(writer.partial) == 0 {
+		return nil
+	}
+
+	err := writer.writeLine(append(writer.partial, '\n'))
+	writer.partial = nil
+
+	return err
+}
+
+func (writer *PrefixWriter) writeLine(line []byte) error {
+	_, err := writer.dst.Write(append(append([]byte{}, writer.prefix...), line...))
+	return err
+}
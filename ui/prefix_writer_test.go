@@ -0,0 +1,52 @@
+package ui_test
+
+import (
+	"bytes"
+
+	. "github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrefixWriter", func() {
+	var (
+		dst    *bytes.Buffer
+		writer *PrefixWriter
+	)
+
+	BeforeEach(func() {
+		dst = new(bytes.Buffer)
+		writer = NewPrefixWriter(dst, "[some-prefix] ")
+	})
+
+	It("prefixes each line", func() {
+		_, err := writer.Write([]byte("one\ntwo\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dst.String()).To(Equal("[some-prefix] one\n[some-prefix] two\n"))
+	})
+
+	It("holds back partial lines until they are complete", func() {
+		_, err := writer.Write([]byte("on"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dst.String()).To(BeEmpty())
+
+		_, err = writer.Write([]byte("e\ntw"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dst.String()).To(Equal("[some-prefix] one\n"))
+	})
+
+	It("terminates a final partial line when flushed", func() {
+		_, err := writer.Write([]byte("one\ntwo"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(writer.Flush()).To(Succeed())
+		Expect(dst.String()).To(Equal("[some-prefix] one\n[some-prefix] two\n"))
+
+		Expect(writer.Flush()).To(Succeed())
+		Expect(dst.String()).To(Equal("[some-prefix] one\n[some-prefix] two\n"))
+	})
+})